@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const artifactsFileName = ".pre-committer-artifacts.json"
+
+// artifacts records what the setup added to the project, so that -uninstall
+// removes only that and leaves files, hooks, scripts and packages the
+// project already had alone.
+type artifacts struct {
+	Files            []string       `json:"files,omitempty"`
+	Hooks            []hookArtifact `json:"hooks,omitempty"`
+	Packages         []string       `json:"packages,omitempty"`
+	ComposerPackages []string       `json:"composerPackages,omitempty"`
+	Scripts          []string       `json:"scripts,omitempty"`
+}
+
+type hookArtifact struct {
+	Path    string `json:"path"`
+	Command string `json:"command"`
+}
+
+func loadArtifacts(path string) (*artifacts, error) {
+	a := &artifacts{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return a, nil
+}
+
+func (a *artifacts) save(path string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func (a *artifacts) addFile(name string) {
+	if a != nil && !slices.Contains(a.Files, name) {
+		a.Files = append(a.Files, name)
+	}
+}
+
+func (a *artifacts) addHook(path, command string) {
+	hook := hookArtifact{path, command}
+	if a != nil && !slices.Contains(a.Hooks, hook) {
+		a.Hooks = append(a.Hooks, hook)
+	}
+}
+
+// newPackages lists the packages that are not dependencies yet; it has to be
+// called before installing them.
+func newPackages(pkgs []string, isDependency func(string) bool) []string {
+	var added []string
+	for _, pkg := range pkgs {
+		if !isDependency(pkg) {
+			added = append(added, pkg)
+		}
+	}
+	return added
+}
+
+func (a *artifacts) addPackages(pkgs ...string) {
+	for _, pkg := range pkgs {
+		if !slices.Contains(a.Packages, pkg) {
+			a.Packages = append(a.Packages, pkg)
+		}
+	}
+}
+
+func (a *artifacts) addScripts(names ...string) {
+	for _, name := range names {
+		if a != nil && !slices.Contains(a.Scripts, name) {
+			a.Scripts = append(a.Scripts, name)
+		}
+	}
+}
+
+func (a *artifacts) addComposerPackages(pkgs ...string) {
+	for _, pkg := range pkgs {
+		if !slices.Contains(a.ComposerPackages, pkg) {
+			a.ComposerPackages = append(a.ComposerPackages, pkg)
+		}
+	}
+}
+
+func hasComposerDependency(name string) bool {
+	data, err := os.ReadFile("composer.json")
+	if err != nil {
+		return false
+	}
+	var composer struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(data, &composer); err != nil {
+		return false
+	}
+	_, inRequire := composer.Require[name]
+	_, inRequireDev := composer.RequireDev[name]
+	return inRequire || inRequireDev
+}
+
+// uninstall removes what earlier setups recorded in the artifacts file,
+// asking before each removal unless -yes is given. What the user keeps stays
+// recorded, so that a later -uninstall can still remove it.
+func uninstall(m model) error {
+	if _, err := os.Stat(artifactsFileName); os.IsNotExist(err) {
+		return fmt.Errorf("nothing to uninstall: %s not found", artifactsFileName)
+	}
+	a, err := loadArtifacts(artifactsFileName)
+	if err != nil {
+		return err
+	}
+	ask := func(prompt string) bool {
+		return m.yes || confirm(prompt+" (y/n): ")
+	}
+	kept := &artifacts{}
+	// Scripts go before the packages, as a prepare script running husky
+	// fails once husky is removed.
+	for _, name := range a.Scripts {
+		if !ask(fmt.Sprintf("Remove the %s script from package.json?", name)) {
+			kept.addScripts(name)
+			continue
+		}
+		if err := m.removePackageScript(name); err != nil {
+			return err
+		}
+	}
+	for _, hook := range a.Hooks {
+		if !ask(fmt.Sprintf("Remove %q from %s?", hook.Command, hook.Path)) {
+			kept.addHook(hook.Path, hook.Command)
+			continue
+		}
+		if err := m.removeHookCommand(hook.Path, hook.Command); err != nil {
+			return err
+		}
+	}
+	if len(a.Packages) > 0 {
+		command := pmRemove(m.packageManager, a.Packages)
+		if !ask(fmt.Sprintf("Run %s?", formatCommand(command[0], command[1:]...))) {
+			kept.addPackages(a.Packages...)
+		} else if err := m.runCommand(command[0], command[1:]...); err != nil {
+			return err
+		} else if slices.Contains(a.Packages, "husky") {
+			// husky pointed git at .husky/_, which is stale once it is
+			// removed. The key is unset already when husky was never
+			// initialised.
+			_ = m.runCommand("git", "config", "--unset", "core.hooksPath")
+		}
+	}
+	if len(a.ComposerPackages) > 0 {
+		command := composerRemove(a.ComposerPackages)
+		if !ask(fmt.Sprintf("Run %s?", formatCommand(command[0], command[1:]...))) {
+			kept.addComposerPackages(a.ComposerPackages...)
+		} else if err := m.runCommand(command[0], command[1:]...); err != nil {
+			return err
+		}
+	}
+	// Files go last, as the package managers need a package.json the setup
+	// created.
+	for _, name := range a.Files {
+		if !ask(fmt.Sprintf("Delete %s?", name)) {
+			kept.addFile(name)
+			continue
+		}
+		if err := m.removeFile(name); err != nil {
+			return err
+		}
+	}
+	if kept.empty() {
+		return m.removeFile(artifactsFileName)
+	}
+	if m.dryRun {
+		return nil
+	}
+	m.infof("Keeping what you chose not to remove in %s\n", artifactsFileName)
+	return kept.save(artifactsFileName)
+}
+
+func (a *artifacts) empty() bool {
+	return len(a.Files) == 0 && len(a.Hooks) == 0 && len(a.Packages) == 0 && len(a.ComposerPackages) == 0 && len(a.Scripts) == 0
+}
+
+// rollback undoes what the current setup run added to files and hooks.
+// Packages are left installed, as removing them could fail the same way.
+func (m model) rollback() {
+	for i := len(m.added.Hooks) - 1; i >= 0; i-- {
+		hook := m.added.Hooks[i]
+		if err := m.removeHookCommand(hook.Path, hook.Command); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not remove %q from %s: %v\n", hook.Command, hook.Path, err)
+		}
+	}
+	for i := len(m.added.Files) - 1; i >= 0; i-- {
+		if err := m.removeFile(m.added.Files[i]); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not remove %s: %v\n", m.added.Files[i], err)
+		}
+	}
+}
+
+// removeHookCommand drops command from the hook at path, and deletes the hook
+// when nothing but the husky header is left.
+func (m model) removeHookCommand(path, command string) error {
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	var kept []string
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) != command {
+			kept = append(kept, line)
+		}
+	}
+	content := strings.Join(kept, "\n")
+	if rest := strings.TrimSpace(content); rest == "" || rest == strings.TrimSpace(huskyHookHeader(8)) || rest == strings.TrimSpace(gitHookHeader) {
+		return m.removeFile(path)
+	}
+	if m.dryRun {
+		m.infof("[dry-run] would remove %q from %s\n", command, path)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+func (m model) removeFile(name string) error {
+	if m.dryRun {
+		m.infof("[dry-run] would delete %s\n", name)
+		return nil
+	}
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("deleting %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// answerPrompts feeds the given lines to the prompts.
+func answerPrompts(t *testing.T, answers ...string) {
+	t.Helper()
+	original := stdin
+	stdin = bufio.NewReader(strings.NewReader(strings.Join(answers, "\n") + "\n"))
+	t.Cleanup(func() { stdin = original })
+}
+
+// recordSetup writes a config file and a hook command the way the setup
+// does, on top of a project that already had a pre-commit hook, and records
+// two installed packages.
+func recordSetup(t *testing.T) model {
+	t.Helper()
+	if err := os.MkdirAll(".husky", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, ".husky/pre-commit", "npm test\n")
+	m := testModel()
+	m.created, m.added = &artifacts{}, &artifacts{}
+	if err := m.writeFile(".eslintrc.js", "module.exports = {};\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ensureHookCommand(".husky/pre-commit", "", "npx lint-staged"); err != nil {
+		t.Fatal(err)
+	}
+	m.created.addPackages("eslint", "husky")
+	m.created.addComposerPackages("squizlabs/php_codesniffer")
+	if err := m.created.save(artifactsFileName); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestArtifactsRecordOnlyWhatWasAdded(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	writeConfig(t, ".prettierrc.js", "custom")
+	m := recordSetup(t)
+	if err := m.writeFile(".prettierrc.js", "generated"); err != nil {
+		t.Fatal(err)
+	}
+	a, err := loadArtifacts(artifactsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(a.Files, []string{".eslintrc.js"}) {
+		t.Errorf("files = %v", a.Files)
+	}
+	if !slices.Equal(a.Hooks, []hookArtifact{{".husky/pre-commit", "npx lint-staged"}}) {
+		t.Errorf("hooks = %v", a.Hooks)
+	}
+	if got := newPackages([]string{"eslint", "react"}, func(pkg string) bool { return pkg == "react" }); !slices.Equal(got, []string{"eslint"}) {
+		t.Errorf("newPackages = %v", got)
+	}
+}
+
+func TestRemoveCommands(t *testing.T) {
+	for pm, want := range map[packageManager][]string{
+		npm:  {"npm", "uninstall", "eslint"},
+		pnpm: {"pnpm", "remove", "eslint"},
+		yarn: {"yarn", "remove", "eslint"},
+		bun:  {"bun", "remove", "eslint"},
+	} {
+		if got := pmRemove(pm, []string{"eslint"}); !slices.Equal(got, want) {
+			t.Errorf("pmRemove(%s) = %v, want %v", pm, got, want)
+		}
+	}
+	if got := composerRemove([]string{"phpstan/phpstan"}); !slices.Equal(got, []string{"composer", "remove", "--dev", "phpstan/phpstan"}) {
+		t.Errorf("composerRemove = %v", got)
+	}
+}
+
+func TestUninstallRemovesWhatWasAdded(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	log := fakePackageManagers(t)
+	fakeCommands(t, map[string]string{"git": `echo "git $*" >> "$COMMANDS_LOG"`})
+	m := recordSetup(t)
+	m.yes = true
+	if err := uninstall(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, ".husky/pre-commit"); got != "npm test\n" {
+		t.Errorf("the project's own hook command was not kept: %q", got)
+	}
+	for _, name := range []string{".eslintrc.js", artifactsFileName} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+	want := "npm uninstall eslint husky\ngit config --unset core.hooksPath\ncomposer remove --dev squizlabs/php_codesniffer\n"
+	if got := readFile(t, log); got != want {
+		t.Errorf("commands run:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUninstallKeepsDeclinedEntriesRecorded(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	log := fakePackageManagers(t)
+	recordSetup(t)
+	// Keep the hook command and the npm packages, remove the rest.
+	answerPrompts(t, "n", "n", "y", "y")
+	if err := uninstall(testModel()); err != nil {
+		t.Fatal(err)
+	}
+	a, err := loadArtifacts(artifactsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &artifacts{
+		Hooks:    []hookArtifact{{".husky/pre-commit", "npx lint-staged"}},
+		Packages: []string{"eslint", "husky"},
+	}
+	if !slices.Equal(a.Hooks, want.Hooks) || !slices.Equal(a.Packages, want.Packages) || len(a.Files) != 0 || len(a.ComposerPackages) != 0 {
+		t.Errorf("artifacts after declining = %+v, want %+v", a, want)
+	}
+	if got := readFile(t, log); got != "composer remove --dev squizlabs/php_codesniffer\n" {
+		t.Errorf("commands run: %q", got)
+	}
+	if _, err := os.Stat(".eslintrc.js"); !os.IsNotExist(err) {
+		t.Errorf(".eslintrc.js was not removed")
+	}
+}
+
+func TestUninstallRemovesAddedScripts(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	writeConfig(t, "package.json", `{"scripts": {"build": "webpack"}}`)
+	m := testModel()
+	m.created, m.added = &artifacts{}, &artifacts{}
+	if err := m.mergePackageJSON(object{{"prepare", "husky"}, {"build", "tsc"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.created.Scripts, []string{"prepare"}) {
+		t.Fatalf("scripts = %v, want only the new prepare script", m.created.Scripts)
+	}
+	if err := m.created.save(artifactsFileName); err != nil {
+		t.Fatal(err)
+	}
+	m.yes = true
+	if err := uninstall(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, "package.json"); got != "{\n  \"scripts\": {\n    \"build\": \"webpack\"\n  }\n}\n" {
+		t.Errorf("package.json = %q", got)
+	}
+}
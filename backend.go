@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hookBackend is the hook manager that runs the checks: husky with
+// lint-staged, lint-staged from plain git hooks, or the pre-commit framework.
+type hookBackend interface {
+	// packages lists the npm packages the backend needs.
+	packages() []string
+	// binaries lists the programs the backend needs on the PATH.
+	binaries() []string
+	// setup installs the hook manager and wires the lint-staged checks and
+	// the other hooks into it.
+	setup(m model, pm packageManager, hooks []hookCommand) error
+	verifyCommand(m model, pm packageManager) []string
+}
+
+const (
+	backendHusky     = "husky"
+	backendPreCommit = "pre-commit"
+	backendGit       = "git"
+)
+
+func parseBackend(value string) (string, error) {
+	switch value {
+	case backendHusky, backendPreCommit, backendGit:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown backend %q (use husky, pre-commit or git)", value)
+}
+
+func (m model) backendName() string {
+	if m.backend == "" {
+		return backendHusky
+	}
+	return m.backend
+}
+
+func (m model) hookBackend() hookBackend {
+	switch m.backend {
+	case backendPreCommit:
+		return preCommitBackend{}
+	case backendGit:
+		return gitHooksBackend{}
+	}
+	return huskyBackend{}
+}
+
+type huskyBackend struct{}
+
+func (huskyBackend) packages() []string {
+	return []string{"husky", "lint-staged"}
+}
+
+func (huskyBackend) binaries() []string {
+	return nil
+}
+
+func (huskyBackend) setup(m model, pm packageManager, hooks []hookCommand) error {
+	m.huskyMajor = m.detectHuskyMajor(pm)
+	huskyInit := huskyInitCommand(pm, m.huskyMajor)
+	if err := m.runCommand(huskyInit[0], huskyInit[1:]...); err != nil {
+		return err
+	}
+	// The prepare script sets the hooks up again for everyone who installs
+	// the project's dependencies after cloning it.
+	if err := m.updatePackageJSON(object{{"prepare", huskyPrepareScript(m.huskyMajor)}}, nil); err != nil {
+		return err
+	}
+	hooks = append([]hookCommand{{"pre-commit", m.lintStagedCommand(pm)}}, hooks...)
+	for _, hook := range m.withCIBailOut(hooks) {
+		if err := m.ensureHookCommand(".husky/"+hook.hook, huskyHookHeader(m.huskyMajor), hook.command); err != nil {
+			return err
+		}
+	}
+	return m.writeLintStagedConfig()
+}
+
+func (huskyBackend) verifyCommand(m model, pm packageManager) []string {
+	return strings.Fields(m.lintStagedCommand(pm, "--allow-empty"))
+}
+
+// ciBailOut ends a hook early on CI servers, which set CI=true. husky hooks
+// are skipped with HUSKY=0 as well.
+const ciBailOut = `if [ "$CI" = "true" ]; then exit 0; fi`
+
+// withCIBailOut starts each hook with ciBailOut for -skip-ci.
+func (m model) withCIBailOut(hooks []hookCommand) []hookCommand {
+	if !m.skipCI {
+		return hooks
+	}
+	var result []hookCommand
+	seen := map[string]bool{}
+	for _, hook := range hooks {
+		if !seen[hook.hook] {
+			seen[hook.hook] = true
+			result = append(result, hookCommand{hook.hook, ciBailOut})
+		}
+		result = append(result, hook)
+	}
+	return result
+}
+
+func (m model) writeLintStagedConfig() error {
+	lintStaged := generateLintStagedConfig(m)
+	if err := validateLintStagedConfig(lintStaged); err != nil {
+		return fmt.Errorf("generated an invalid lint-staged config: %w", err)
+	}
+	return m.writeFile(m.configFile("lint-staged"), lintStaged)
+}
+
+// gitHooksBackend runs lint-staged from hooks written straight into
+// .git/hooks, for projects that do not want husky. The hooks are not part of
+// the repository, so each clone has to run the setup itself.
+type gitHooksBackend struct{}
+
+const gitHookHeader = "#!/bin/sh\n"
+
+func (gitHooksBackend) packages() []string {
+	return []string{"lint-staged"}
+}
+
+func (gitHooksBackend) binaries() []string {
+	return nil
+}
+
+func (gitHooksBackend) setup(m model, pm packageManager, hooks []hookCommand) error {
+	dir, err := gitHooksDir()
+	if err != nil {
+		return err
+	}
+	if hooksPath := gitConfigValue("core.hooksPath"); hooksPath != "" {
+		m.infof("git runs the hooks in %s instead of %s; unset core.hooksPath to use them: git config --unset core.hooksPath\n", hooksPath, dir)
+	}
+	hooks = append([]hookCommand{{"pre-commit", m.lintStagedCommand(pm)}}, hooks...)
+	for _, hook := range m.withCIBailOut(hooks) {
+		if err := m.ensureHookCommand(path.Join(dir, hook.hook), gitHookHeader, hook.command); err != nil {
+			return err
+		}
+	}
+	return m.writeLintStagedConfig()
+}
+
+func (gitHooksBackend) verifyCommand(m model, pm packageManager) []string {
+	return strings.Fields(m.lintStagedCommand(pm, "--allow-empty"))
+}
+
+// gitHooksDir is the hooks directory of the repository, which worktrees
+// share with the main checkout.
+func gitHooksDir() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding the git directory: %w", err)
+	}
+	return path.Join(filepath.ToSlash(strings.TrimSpace(string(output))), "hooks"), nil
+}
+
+func gitConfigValue(key string) string {
+	output, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// lintStagedCommand runs lint-staged with its config and the -concurrency
+// limit. lint-staged itself runs all globs at once by default.
+func (m model) lintStagedCommand(pm packageManager, args ...string) string {
+	if m.concurrency > 0 {
+		args = append(args, "--concurrent", strconv.Itoa(m.concurrency))
+	}
+	return m.withConfig("lint-staged", strings.Join(pmExec(pm, append([]string{"lint-staged"}, args...)...), " "))
+}
+
+// preCommitBackend runs the same checks as lint-staged would, as local hooks
+// of the pre-commit framework that use the tools installed in the project.
+type preCommitBackend struct{}
+
+func (preCommitBackend) packages() []string {
+	return nil
+}
+
+func (preCommitBackend) binaries() []string {
+	return []string{"pre-commit"}
+}
+
+func (preCommitBackend) setup(m model, pm packageManager, hooks []hookCommand) error {
+	if err := m.writeFile(".pre-commit-config.yaml", generatePreCommitConfig(m, pm, hooks)); err != nil {
+		return err
+	}
+	install := []string{"pre-commit", "install", "--hook-type", "pre-commit"}
+	for _, stage := range preCommitStages(hooks) {
+		if stage != "pre-commit" {
+			install = append(install, "--hook-type", stage)
+		}
+	}
+	return m.runCommand(install[0], install[1:]...)
+}
+
+func (preCommitBackend) verifyCommand(m model, pm packageManager) []string {
+	return []string{"pre-commit", "run"}
+}
+
+type preCommitConfig struct {
+	Repos []preCommitRepo `yaml:"repos"`
+}
+
+type preCommitRepo struct {
+	Repo  string          `yaml:"repo"`
+	Hooks []preCommitHook `yaml:"hooks"`
+}
+
+type preCommitHook struct {
+	ID            string   `yaml:"id"`
+	Name          string   `yaml:"name"`
+	Entry         string   `yaml:"entry"`
+	Language      string   `yaml:"language"`
+	Files         string   `yaml:"files,omitempty"`
+	Stages        []string `yaml:"stages,omitempty"`
+	PassFilenames *bool    `yaml:"pass_filenames,omitempty"`
+	AlwaysRun     bool     `yaml:"always_run,omitempty"`
+}
+
+// nodeTools are the lint-staged commands installed from npm. lint-staged
+// finds them in node_modules/.bin, pre-commit needs them run through the
+// package manager.
+var nodeTools = map[string]bool{
+	"eslint":               true,
+	"prettier":             true,
+	"stylelint":            true,
+	"secretlint":           true,
+	"markdownlint":         true,
+	"cspell":               true,
+	"biome":                true,
+	"imagemin-lint-staged": true,
+}
+
+func generatePreCommitConfig(m model, pm packageManager, hooks []hookCommand) string {
+	// Commands that run for several globs become one hook matching all of
+	// them, listed where the command first runs so the order within a glob
+	// is kept.
+	var commands []string
+	patterns := map[string][]string{}
+	for _, f := range lintStagedConfig(m) {
+		for _, command := range f.value.([]string) {
+			if _, ok := patterns[command]; !ok {
+				commands = append(commands, command)
+			}
+			patterns[command] = append(patterns[command], globToRegexp(f.key))
+		}
+	}
+	ids := map[string]int{}
+	id := func(name string) string {
+		if ids[name]++; ids[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, ids[name])
+		}
+		return name
+	}
+	var local []preCommitHook
+	for _, command := range commands {
+		entry := command
+		if nodeTools[strings.Fields(command)[0]] {
+			entry = strings.Join(pmExec(pm, command), " ")
+		}
+		files := patterns[command][0]
+		if len(patterns[command]) > 1 {
+			files = "(" + strings.Join(patterns[command], "|") + ")"
+		}
+		// Hooks without stages would run in every installed hook, so the
+		// staged file checks are limited to pre-commit like with lint-staged.
+		name := commandName(command, pm)
+		local = append(local, preCommitHook{
+			ID:       id(name),
+			Name:     name,
+			Entry:    entry,
+			Language: "system",
+			Files:    files,
+			Stages:   []string{"pre-commit"},
+		})
+	}
+	noFilenames := false
+	for _, hook := range hooks {
+		name := commandName(hook.command, pm)
+		h := preCommitHook{
+			ID:       id(name),
+			Name:     name,
+			Entry:    hook.command,
+			Language: "system",
+			Stages:   []string{hook.hook},
+		}
+		switch hook.hook {
+		case "commit-msg", "prepare-commit-msg":
+			// pre-commit passes the message file as the only file name, in
+			// place of the "$1" husky hooks use.
+			h.Entry = strings.TrimSuffix(hook.command, ` "$1"`)
+		default:
+			h.PassFilenames = &noFilenames
+			h.AlwaysRun = true
+			if strings.ContainsAny(hook.command, "|$") {
+				// pre-commit runs entries without a shell.
+				h.Entry = "sh -c '" + strings.ReplaceAll(hook.command, "'", `'\''`) + "'"
+			}
+		}
+		local = append(local, h)
+	}
+	return encodeYAML(preCommitConfig{Repos: []preCommitRepo{{Repo: "local", Hooks: local}}})
+}
+
+func preCommitStages(hooks []hookCommand) []string {
+	var stages []string
+	seen := map[string]bool{}
+	for _, hook := range hooks {
+		if !seen[hook.hook] {
+			seen[hook.hook] = true
+			stages = append(stages, hook.hook)
+		}
+	}
+	return stages
+}
+
+// commandName names a hook after the program its command runs.
+func commandName(command string, pm packageManager) string {
+	command = strings.TrimPrefix(command, strings.Join(pmExec(pm), " ")+" ")
+	fields := strings.Fields(strings.TrimPrefix(command, "sh -c '"))
+	name := fields[0]
+	if strings.HasPrefix(command, "sh -c '") && !strings.HasSuffix(command, "'") {
+		// Shell wrappers are named by the $0 after the script.
+		name = fields[len(fields)-1]
+	}
+	if (name == "node" || name == "sh") && len(fields) > 1 {
+		name = strings.TrimSuffix(fields[1], path.Ext(fields[1]))
+	}
+	return strings.TrimPrefix(path.Base(name), ".")
+}
+
+// globToRegexp converts a lint-staged glob into the regular expression
+// pre-commit matches file paths against. Like lint-staged, globs without a
+// slash match the file name in any directory.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	if strings.Contains(glob, "/") {
+		b.WriteString("^")
+	} else {
+		b.WriteString("(^|/)")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '{':
+			b.WriteString("(")
+		case c == '}':
+			b.WriteString(")")
+		case c == ',' && strings.Contains(glob[:i], "{"):
+			b.WriteString("|")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
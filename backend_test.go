@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCommand(t *testing.T) {
+	m := testModel()
+	tests := []struct {
+		backend hookBackend
+		pm      packageManager
+		want    []string
+	}{
+		{huskyBackend{}, npm, []string{"npx", "lint-staged", "--allow-empty"}},
+		{huskyBackend{}, pnpm, []string{"pnpm", "exec", "lint-staged", "--allow-empty"}},
+		{gitHooksBackend{}, npm, []string{"npx", "lint-staged", "--allow-empty"}},
+		{preCommitBackend{}, npm, []string{"pre-commit", "run"}},
+	}
+	for _, tt := range tests {
+		if got := tt.backend.verifyCommand(m, tt.pm); !slices.Equal(got, tt.want) {
+			t.Errorf("%T with %s: %v, want %v", tt.backend, tt.pm, got, tt.want)
+		}
+	}
+}
+
+func TestPreCommitConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["commitlint"], m.enabled["typescript"] = true, true, true, true
+	want := `repos:
+  - repo: local
+    hooks:
+      - id: eslint
+        name: eslint
+        entry: npx eslint --fix
+        language: system
+        files: (^|/)[^/]*\.js$
+        stages:
+          - pre-commit
+      - id: prettier
+        name: prettier
+        entry: npx prettier --write
+        language: system
+        files: (^|/)[^/]*\.js$
+        stages:
+          - pre-commit
+      - id: commitlint
+        name: commitlint
+        entry: npx commitlint --edit
+        language: system
+        stages:
+          - commit-msg
+      - id: tsc
+        name: tsc
+        entry: npx tsc --noEmit
+        language: system
+        stages:
+          - pre-push
+        pass_filenames: false
+        always_run: true
+`
+	if got := generatePreCommitConfig(m, npm, m.hookCommands(npm)); got != want {
+		t.Errorf("generated config:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPreCommitInstallsEachHookType(t *testing.T) {
+	inTempDir(t)
+	fakeCommands(t, map[string]string{"pre-commit": `echo "$*" > pre-commit.args`})
+	m := testModel()
+	m.enabled["eslint"], m.enabled["commitlint"], m.enabled["typescript"] = true, true, true
+	if err := (preCommitBackend{}).setup(m, npm, m.hookCommands(npm)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(".pre-commit-config.yaml"); err != nil {
+		t.Error(err)
+	}
+	want := "install --hook-type pre-commit --hook-type commit-msg --hook-type pre-push\n"
+	if got := readFile(t, "pre-commit.args"); got != want {
+		t.Errorf("pre-commit was run with %q, want %q", got, want)
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{"*.js", []string{"a.js", "src/a.js"}, []string{"a.jsx", "a.js/b"}},
+		{"*.{js,jsx}", []string{"a.js", "lib/a.jsx"}, []string{"a.ts"}},
+		{"web/**/*.php", []string{"web/a.php", "web/modules/a.php"}, []string{"a.php", "other/web/a.php"}},
+		{"Dockerfile*", []string{"Dockerfile", "docker/Dockerfile.dev"}, []string{"my.Dockerfile"}},
+		{"?.md", []string{"a.md"}, []string{"ab.md"}},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(globToRegexp(tt.glob))
+		for _, name := range tt.match {
+			if !re.MatchString(name) {
+				t.Errorf("%s (%s) does not match %s", tt.glob, re, name)
+			}
+		}
+		for _, name := range tt.noMatch {
+			if re.MatchString(name) {
+				t.Errorf("%s (%s) matches %s", tt.glob, re, name)
+			}
+		}
+	}
+	if got := globToRegexp("*.min.js"); !strings.Contains(got, `\.min\.js`) {
+		t.Errorf("dots are not escaped in %s", got)
+	}
+}
+
+func TestConcurrencyIsPassedToLintStaged(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["eslint"] = true
+	concurrency, err := parseConcurrency("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.concurrency = concurrency
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-commit"); got != "npx lint-staged --concurrent 2\n" {
+		t.Errorf(".husky/pre-commit = %q", got)
+	}
+	if got := (huskyBackend{}).verifyCommand(m, npm); !slices.Equal(got, []string{"npx", "lint-staged", "--allow-empty", "--concurrent", "2"}) {
+		t.Errorf("verify command = %v", got)
+	}
+	for _, value := range []string{"0", "-1", "all"} {
+		if _, err := parseConcurrency(value); err == nil {
+			t.Errorf("concurrency %q is accepted", value)
+		}
+	}
+}
+
+func TestGitHooksBackendWritesExecutableHooks(t *testing.T) {
+	inTempDir(t)
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	m := testModel()
+	m.enabled["eslint"], m.enabled["commitlint"] = true, true
+	for i := 0; i < 2; i++ {
+		if err := (gitHooksBackend{}).setup(m, npm, m.hookCommands(npm)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for hook, want := range map[string]string{
+		"pre-commit": "#!/bin/sh\nnpx lint-staged\n",
+		"commit-msg": "#!/bin/sh\nnpx commitlint --edit \"$1\"\n",
+	} {
+		name := ".git/hooks/" + hook
+		if got := readFile(t, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0755 {
+			t.Errorf("%s has mode %o, want 755", name, mode)
+		}
+	}
+	if _, err := os.Stat(".husky"); err == nil {
+		t.Error("the git backend created .husky")
+	}
+}
+
+func TestSkipCIBailsOutOfTheHooks(t *testing.T) {
+	inTempDir(t)
+	if err := os.MkdirAll(".husky", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, ".husky/pre-commit", "npm test\n")
+	m := testModel()
+	m.enabled["eslint"], m.enabled["commitlint"], m.skipCI = true, true, true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-commit"); got != ciBailOut+"\nnpm test\nnpx lint-staged\n" {
+		t.Errorf(".husky/pre-commit = %q", got)
+	}
+	if got := readFile(t, ".husky/commit-msg"); !strings.HasPrefix(got, ciBailOut+"\n") {
+		t.Errorf(".husky/commit-msg = %q", got)
+	}
+
+	// The hook stops before running anything when CI=true.
+	fakeCommands(t, map[string]string{"npm": "exit 1", "npx": "exit 1"})
+	hook := exec.Command("sh", ".husky/pre-commit")
+	hook.Env = append(os.Environ(), "CI=true")
+	if err := hook.Run(); err != nil {
+		t.Errorf("the hook runs with CI=true: %v", err)
+	}
+	hook = exec.Command("sh", ".husky/pre-commit")
+	hook.Env = append(os.Environ(), "CI=")
+	if err := hook.Run(); err == nil {
+		t.Error("the hook is skipped outside CI")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checklistOptions are the tools of the wizard's checklist, which leaves out
+// the follow-up tools.
+func checklistOptions() []tool {
+	var options []tool
+	for _, t := range toolRegistry() {
+		if !t.followUp {
+			options = append(options, t)
+		}
+	}
+	return options
+}
+
+func (m model) updateToolChoice(msg tea.KeyMsg) model {
+	options := checklistOptions()
+	switch msg.String() {
+	case "up", "k":
+		if m.toolCursor > 0 {
+			m.toolCursor--
+		}
+	case "down", "j":
+		if m.toolCursor < len(options)-1 {
+			m.toolCursor++
+		}
+	case " ", "x":
+		name := options[m.toolCursor].name
+		m.enabled[name] = !m.enabled[name]
+	case "enter":
+		return m.nextQuestion()
+	case "esc", "left":
+		return m.previousQuestion()
+	}
+	return m
+}
+
+func (m model) toolsView(question string) string {
+	var b strings.Builder
+	b.WriteString(question + "\n\n")
+	for i, option := range checklistOptions() {
+		cursor := "  "
+		if i == m.toolCursor {
+			cursor = "> "
+		}
+		check := "[ ]"
+		if m.enabled[option.name] {
+			check = "[x]"
+		}
+		fmt.Fprintf(&b, "%s%s %s: %s\n", cursor, check, option.name, option.usage)
+	}
+	b.WriteString("\n(↑/↓ to move, Space to toggle, Enter to confirm, Esc to go back)\n")
+	return b.String()
+}
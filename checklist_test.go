@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecklistToggleAndConfirm(t *testing.T) {
+	m := atQuestion(toolsQuestion)
+	m.choosePreset("none")
+	m = moveToTool(t, m, "prettier")
+	m, _ = press(t, m, "space")
+	m = moveToTool(t, m, "commitlint")
+	m, _ = press(t, m, "x")
+	m = moveToTool(t, m, "go")
+	m, _ = press(t, m, "space", "space")
+	if !strings.Contains(m.View(), "> [ ] go:") || !strings.Contains(m.View(), "  [x] commitlint:") {
+		t.Errorf("the checklist does not show the toggles:\n%s", m.View())
+	}
+	m, _ = press(t, m, "enter")
+	if m.enabled["prettier"] || !m.enabled["commitlint"] || m.enabled["go"] || !m.enabled["eslint"] {
+		t.Errorf("prettier=%v commitlint=%v go=%v eslint=%v", m.enabled["prettier"], m.enabled["commitlint"], m.enabled["go"], m.enabled["eslint"])
+	}
+	if m.index != nodeVersionQuestion {
+		t.Errorf("Enter moves to question %d", m.index)
+	}
+}
+
+func TestChecklistCursorStaysInRange(t *testing.T) {
+	m := atQuestion(toolsQuestion)
+	m, _ = press(t, m, "up")
+	if m.toolCursor != 0 {
+		t.Errorf("cursor = %d after moving up from the top", m.toolCursor)
+	}
+	last := len(checklistOptions()) - 1
+	for i := 0; i <= last+1; i++ {
+		m, _ = press(t, m, "down")
+	}
+	if m.toolCursor != last {
+		t.Errorf("cursor = %d, want %d", m.toolCursor, last)
+	}
+	// Follow-up tools have their own question.
+	for _, option := range checklistOptions() {
+		if option.name == "prettier-php" {
+			t.Errorf("prettier-php is in the checklist")
+		}
+	}
+}
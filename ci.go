@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ciStep is a check CI runs on the whole project, mirroring what the hooks
+// run on staged files.
+type ciStep struct {
+	tool    string
+	name    string
+	run     string
+	runtime string
+}
+
+// Runtimes a CI step needs, which decide the setup steps or images.
+const (
+	runtimeNode   = "node"
+	runtimePHP    = "php"
+	runtimePython = "python"
+	runtimeGo     = "go"
+	runtimeShell  = "shell"
+)
+
+// ciSteps lists the checks for the selected tools. Standalone binaries like
+// gitleaks and hadolint are left out as CI images do not ship them, apart
+// from shellcheck, which most do.
+func ciSteps(m model, pm packageManager) []ciStep {
+	var steps []ciStep
+	for _, t := range m.enabledTools() {
+		if t.ci == nil {
+			continue
+		}
+		for _, step := range t.ci(m, pm) {
+			step.tool = t.name
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// execCommand runs a package's binary with the package manager.
+func execCommand(pm packageManager, args ...string) string {
+	return strings.Join(pmExec(pm, args...), " ")
+}
+
+func parsePrePush(tools []string) (map[string]bool, error) {
+	prePush := map[string]bool{}
+	for _, tool := range tools {
+		if !slices.Contains(prePushTools(), tool) {
+			return nil, fmt.Errorf("%s cannot run on pre-push (available: %s)", tool, strings.Join(prePushTools(), ", "))
+		}
+		prePush[tool] = true
+	}
+	return prePush, nil
+}
+
+func ciRuntimes(steps []ciStep) map[string]bool {
+	runtimes := map[string]bool{}
+	for _, step := range steps {
+		runtimes[step.runtime] = true
+	}
+	return runtimes
+}
+
+type githubWorkflow struct {
+	Name string               `yaml:"name"`
+	On   []string             `yaml:"on"`
+	Jobs map[string]githubJob `yaml:"jobs"`
+}
+
+type githubJob struct {
+	RunsOn string       `yaml:"runs-on"`
+	Steps  []githubStep `yaml:"steps"`
+}
+
+type githubStep struct {
+	Name string            `yaml:"name,omitempty"`
+	Uses string            `yaml:"uses,omitempty"`
+	With map[string]string `yaml:"with,omitempty"`
+	Run  string            `yaml:"run,omitempty"`
+}
+
+func generateGitHubWorkflow(m model, pm packageManager) string {
+	steps := ciSteps(m, pm)
+	runtimes := ciRuntimes(steps)
+	job := githubJob{RunsOn: "ubuntu-latest", Steps: []githubStep{{Uses: "actions/checkout@v4"}}}
+	if runtimes[runtimeNode] {
+		switch pm {
+		case pnpm:
+			step := githubStep{Uses: "pnpm/action-setup@v4"}
+			if version := m.pnpmVersion(); version != "" {
+				step.With = map[string]string{"version": version}
+			}
+			job.Steps = append(job.Steps, step)
+		case bun:
+			job.Steps = append(job.Steps, githubStep{Uses: "oven-sh/setup-bun@v2"})
+		}
+		setupNode := map[string]string{"node-version": "lts/*"}
+		if m.nodeVersion != "" {
+			setupNode = map[string]string{"node-version-file": ".nvmrc"}
+		}
+		job.Steps = append(job.Steps, githubStep{Uses: "actions/setup-node@v4", With: setupNode})
+		if pm == yarnBerry {
+			// The runners come with Yarn 1, corepack switches to the
+			// project's Yarn.
+			job.Steps = append(job.Steps, githubStep{Run: "corepack enable"})
+		}
+		job.Steps = append(job.Steps, githubStep{Run: strings.Join(pmCleanInstall(pm), " ")})
+	}
+	if runtimes[runtimePHP] {
+		job.Steps = append(job.Steps,
+			githubStep{Uses: "shivammathur/setup-php@v2", With: map[string]string{"tools": "composer"}},
+			githubStep{Run: "composer install --no-interaction --no-progress"},
+		)
+	}
+	if runtimes[runtimePython] {
+		job.Steps = append(job.Steps,
+			githubStep{Uses: "actions/setup-python@v5", With: map[string]string{"python-version": "3.x"}},
+			githubStep{Run: "pip install -r requirements-dev.txt"},
+		)
+	}
+	if runtimes[runtimeGo] {
+		job.Steps = append(job.Steps,
+			githubStep{Uses: "actions/setup-go@v5", With: map[string]string{"go-version": "stable"}},
+			githubStep{Run: "go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest"},
+		)
+	}
+	for _, step := range steps {
+		job.Steps = append(job.Steps, githubStep{Name: step.name, Run: step.run})
+	}
+	return encodeYAML(githubWorkflow{
+		Name: "Lint",
+		On:   []string{"push", "pull_request"},
+		Jobs: map[string]githubJob{"lint": job},
+	})
+}
+
+func encodeYAML(value any) string {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	// Only structs of strings, lists and maps are encoded, which always
+	// succeeds.
+	_ = encoder.Encode(value)
+	return b.String()
+}
+
+type gitlabJob struct {
+	Stage        string   `yaml:"stage"`
+	Image        string   `yaml:"image"`
+	BeforeScript []string `yaml:"before_script,omitempty"`
+	Script       []string `yaml:"script"`
+}
+
+// generateGitLabCI writes one job per runtime, as GitLab runs each job in a
+// single image.
+func generateGitLabCI(m model, pm packageManager) string {
+	jobs := map[string]gitlabJob{}
+	for _, step := range ciSteps(m, pm) {
+		name := "lint:" + step.runtime
+		job, ok := jobs[name]
+		if !ok {
+			job = gitlabRuntimeJob(step.runtime, pm)
+		}
+		job.Script = append(job.Script, step.run)
+		jobs[name] = job
+	}
+	return encodeYAML(struct {
+		Stages []string `yaml:"stages"`
+	}{[]string{"lint"}}) + "\n" + encodeYAML(jobs)
+}
+
+// latestPnpmMajor is installed in CI when the pnpm version cannot be
+// detected.
+const latestPnpmMajor = "10"
+
+// pnpmVersion is the version pnpm/action-setup installs: the major version
+// of the local pnpm. It is left out when package.json names one in its
+// packageManager field, which the action reads and will not combine with a
+// version of its own.
+func (m model) pnpmVersion() string {
+	if packageManagerField() != "" {
+		return ""
+	}
+	output, err := m.commandOutput("pnpm", "--version")
+	if major, _, _ := strings.Cut(strings.TrimSpace(output), "."); err == nil && major != "" {
+		return major
+	}
+	return latestPnpmMajor
+}
+
+func gitlabRuntimeJob(runtime string, pm packageManager) gitlabJob {
+	job := gitlabJob{Stage: "lint"}
+	switch runtime {
+	case runtimeNode:
+		job.Image = "node:lts"
+		if pm == bun {
+			job.Image = "oven/bun:1"
+		}
+		if pm == pnpm || pm == yarnBerry {
+			job.BeforeScript = append(job.BeforeScript, "corepack enable")
+		}
+		job.BeforeScript = append(job.BeforeScript, strings.Join(pmCleanInstall(pm), " "))
+	case runtimePHP:
+		job.Image = "composer:2"
+		job.BeforeScript = []string{"composer install --no-interaction --no-progress"}
+	case runtimePython:
+		job.Image = "python:3"
+		job.BeforeScript = []string{"pip install -r requirements-dev.txt"}
+	case runtimeGo:
+		job.Image = "golang:latest"
+		job.BeforeScript = []string{"go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest"}
+	case runtimeShell:
+		job.Image = "alpine:latest"
+		job.BeforeScript = []string{"apk add --no-cache git shellcheck"}
+	}
+	return job
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parseWorkflow(t *testing.T, m model, pm packageManager) githubJob {
+	t.Helper()
+	var workflow githubWorkflow
+	if err := yaml.Unmarshal([]byte(generateGitHubWorkflow(m, pm)), &workflow); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(workflow.On, []string{"push", "pull_request"}) {
+		t.Errorf("the workflow runs on %v", workflow.On)
+	}
+	return workflow.Jobs["lint"]
+}
+
+func TestGitHubWorkflowHasAStepPerTool(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["phpcs"], m.enabled["python"] = true, true, true, true
+	job := parseWorkflow(t, m, npm)
+	var names, uses []string
+	for _, step := range job.Steps {
+		if step.Name != "" {
+			names = append(names, step.Name)
+		}
+		if step.Uses != "" {
+			uses = append(uses, step.Uses)
+		}
+	}
+	want := []string{"eslint", "prettier", "phpcs", "black", "isort", "flake8"}
+	if !slices.Equal(names, want) {
+		t.Errorf("steps %v, want %v", names, want)
+	}
+	for _, action := range []string{"actions/checkout@v4", "actions/setup-node@v4", "shivammathur/setup-php@v2", "actions/setup-python@v5"} {
+		if !slices.Contains(uses, action) {
+			t.Errorf("%s is not used: %v", action, uses)
+		}
+	}
+	if slices.Contains(uses, "actions/setup-go@v5") {
+		t.Errorf("Go is set up without the Go tools")
+	}
+}
+
+func TestGitHubWorkflowEnablesCorepackForYarnBerry(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"] = true
+	for _, pm := range []packageManager{yarnBerry, npm} {
+		var runs []string
+		for _, step := range parseWorkflow(t, m, pm).Steps {
+			runs = append(runs, step.Run)
+		}
+		corepack := slices.Index(runs, "corepack enable")
+		if pm == yarnBerry && (corepack < 0 || corepack > slices.Index(runs, "yarn install --immutable")) {
+			t.Errorf("corepack is not enabled before installing: %v", runs)
+		}
+		if pm != yarnBerry && corepack >= 0 {
+			t.Errorf("%s enables corepack", pm)
+		}
+	}
+}
+
+func TestGitHubWorkflowPnpmVersion(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["eslint"] = true
+	pnpmVersion := func() string {
+		for _, step := range parseWorkflow(t, m, pnpm).Steps {
+			if step.Uses == "pnpm/action-setup@v4" {
+				return step.With["version"]
+			}
+		}
+		t.Fatal("pnpm is not set up")
+		return ""
+	}
+	fakeCommands(t, map[string]string{"pnpm": "echo 9.15.0"})
+	if got := pnpmVersion(); got != "9" {
+		t.Errorf("version %q, want the local major version 9", got)
+	}
+	fakeCommands(t, map[string]string{"pnpm": "exit 1"})
+	if got := pnpmVersion(); got != latestPnpmMajor {
+		t.Errorf("version %q without a local pnpm, want %s", got, latestPnpmMajor)
+	}
+	if err := os.WriteFile("package.json", []byte(`{"packageManager": "pnpm@9.15.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := pnpmVersion(); got != "" {
+		t.Errorf("version %q next to the packageManager field, want none", got)
+	}
+}
+
+func TestGitLabCIHasAJobPerRuntime(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["phpcs"] = true, true, true
+	var config struct {
+		Stages []string             `yaml:"stages"`
+		Jobs   map[string]gitlabJob `yaml:",inline"`
+	}
+	if err := yaml.Unmarshal([]byte(generateGitLabCI(m, pnpm)), &config); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.Stages, []string{"lint"}) {
+		t.Errorf("stages %v", config.Stages)
+	}
+	want := map[string]gitlabJob{
+		"lint:node": {
+			Stage:        "lint",
+			Image:        "node:lts",
+			BeforeScript: []string{"corepack enable", "pnpm install --frozen-lockfile"},
+			Script:       []string{"pnpm exec eslint .", "pnpm exec prettier --check ."},
+		},
+		"lint:php": {
+			Stage:        "lint",
+			Image:        "composer:2",
+			BeforeScript: []string{"composer install --no-interaction --no-progress"},
+			Script:       []string{"vendor/bin/phpcs --standard=phpcs.xml"},
+		},
+	}
+	if len(config.Jobs) != len(want) {
+		t.Errorf("jobs %v, want %v", config.Jobs, want)
+	}
+	for name, job := range want {
+		got := config.Jobs[name]
+		if got.Stage != job.Stage || got.Image != job.Image || !slices.Equal(got.BeforeScript, job.BeforeScript) || !slices.Equal(got.Script, job.Script) {
+			t.Errorf("%s = %+v, want %+v", name, got, job)
+		}
+	}
+}
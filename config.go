@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = ".pre-committer.yml"
+
+// fileConfig is the schema of .pre-committer.yml and of the global config.
+// Tools are keyed by their flag names, e.g.
+//
+//	docroots: [web]
+//	packageManager: pnpm
+//	tools:
+//	  eslint: true
+//	  phpcs: true
+type fileConfig struct {
+	Docroot             string              `yaml:"docroot,omitempty"`
+	Docroots            []string            `yaml:"docroots,omitempty"`
+	DocrootCandidates   []string            `yaml:"docrootCandidates,omitempty"`
+	PackageManager      string              `yaml:"packageManager,omitempty"`
+	Backend             string              `yaml:"backend,omitempty"`
+	Preset              string              `yaml:"preset,omitempty"`
+	ConfigFormat        string              `yaml:"configFormat,omitempty"`
+	PhpstanLevel        *int                `yaml:"phpstanLevel,omitempty"`
+	LicenseTemplate     string              `yaml:"licenseTemplate,omitempty"`
+	JiraKey             string              `yaml:"jiraKey,omitempty"`
+	Tools               map[string]bool     `yaml:"tools,omitempty"`
+	Extensions          map[string][]string `yaml:"extensions,omitempty"`
+	PrePush             []string            `yaml:"prePush,omitempty"`
+	Pin                 *bool               `yaml:"pin,omitempty"`
+	Versions            map[string]string   `yaml:"versions,omitempty"`
+	LintStagedFunctions *bool               `yaml:"lintStagedFunctions,omitempty"`
+	ConfigDir           string              `yaml:"configDir,omitempty"`
+	PhpcsStandard       string              `yaml:"phpcsStandard,omitempty"`
+	ProtectedBranches   []string            `yaml:"protectedBranches,omitempty"`
+	Concurrency         int                 `yaml:"concurrency,omitempty"`
+	NodeVersion         string              `yaml:"nodeVersion,omitempty"`
+	SkipCI              *bool               `yaml:"skipCI,omitempty"`
+}
+
+// globalConfigPath is where defaults shared by all repositories live.
+func globalConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pre-committer", "config.yml"), nil
+}
+
+// readConfig decodes a config file and checks its values, so that errors
+// name the file they come from even after configs are merged.
+func readConfig(path string) (fileConfig, error) {
+	var cfg fileConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading %s: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && err != io.EOF {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	m := initialModel()
+	if err := cfg.apply(&m); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// loadConfig reads a single config file into a model with the defaults.
+func loadConfig(path string) (model, error) {
+	m := initialModel()
+	cfg, err := readConfig(path)
+	if err != nil {
+		return m, err
+	}
+	// readConfig checked the values, so applying them succeeds.
+	_ = cfg.apply(&m)
+	return m, nil
+}
+
+// mergeConfigs layers configs in increasing precedence: each value set in a
+// later config replaces the one from earlier configs. Tools and extensions
+// are merged per tool.
+func mergeConfigs(configs ...fileConfig) fileConfig {
+	var merged fileConfig
+	for _, cfg := range configs {
+		if cfg.Docroot != "" || len(cfg.Docroots) > 0 {
+			merged.Docroot, merged.Docroots = cfg.Docroot, cfg.Docroots
+		}
+		if len(cfg.DocrootCandidates) > 0 {
+			merged.DocrootCandidates = cfg.DocrootCandidates
+		}
+		if cfg.PackageManager != "" {
+			merged.PackageManager = cfg.PackageManager
+		}
+		if cfg.Backend != "" {
+			merged.Backend = cfg.Backend
+		}
+		if cfg.Preset != "" {
+			merged.Preset = cfg.Preset
+		}
+		if cfg.ConfigFormat != "" {
+			merged.ConfigFormat = cfg.ConfigFormat
+		}
+		if cfg.PhpstanLevel != nil {
+			merged.PhpstanLevel = cfg.PhpstanLevel
+		}
+		if cfg.LicenseTemplate != "" {
+			merged.LicenseTemplate = cfg.LicenseTemplate
+		}
+		if cfg.JiraKey != "" {
+			merged.JiraKey = cfg.JiraKey
+		}
+		if len(cfg.PrePush) > 0 {
+			merged.PrePush = cfg.PrePush
+		}
+		// Switches are pointers so that a later config can turn them off.
+		if cfg.Pin != nil {
+			merged.Pin = cfg.Pin
+		}
+		if cfg.LintStagedFunctions != nil {
+			merged.LintStagedFunctions = cfg.LintStagedFunctions
+		}
+		if cfg.SkipCI != nil {
+			merged.SkipCI = cfg.SkipCI
+		}
+		if cfg.ConfigDir != "" {
+			merged.ConfigDir = cfg.ConfigDir
+		}
+		if cfg.PhpcsStandard != "" {
+			merged.PhpcsStandard = cfg.PhpcsStandard
+		}
+		if len(cfg.ProtectedBranches) > 0 {
+			merged.ProtectedBranches = cfg.ProtectedBranches
+		}
+		if cfg.Concurrency != 0 {
+			merged.Concurrency = cfg.Concurrency
+		}
+		if cfg.NodeVersion != "" {
+			merged.NodeVersion = cfg.NodeVersion
+		}
+		for pkg, version := range cfg.Versions {
+			if merged.Versions == nil {
+				merged.Versions = map[string]string{}
+			}
+			merged.Versions[pkg] = version
+		}
+		for tool, enabled := range cfg.Tools {
+			if merged.Tools == nil {
+				merged.Tools = map[string]bool{}
+			}
+			merged.Tools[tool] = enabled
+		}
+		for tool, extensions := range cfg.Extensions {
+			if merged.Extensions == nil {
+				merged.Extensions = map[string][]string{}
+			}
+			merged.Extensions[tool] = extensions
+		}
+	}
+	return merged
+}
+
+func (cfg fileConfig) apply(m *model) error {
+	if cfg.Preset != "" {
+		if err := applyPreset(m, cfg.Preset); err != nil {
+			return err
+		}
+	}
+	if cfg.Docroot != "" {
+		m.docroots = []string{cleanDocroot(cfg.Docroot)}
+	}
+	if len(cfg.Docroots) > 0 {
+		m.docroots = nil
+		for _, docroot := range cfg.Docroots {
+			m.docroots = append(m.docroots, cleanDocroot(docroot))
+		}
+	}
+	if len(cfg.DocrootCandidates) > 0 {
+		m.docrootCandidates = cfg.DocrootCandidates
+	}
+	if cfg.PackageManager != "" {
+		pm, err := parsePackageManager(cfg.PackageManager)
+		if err != nil {
+			return err
+		}
+		m.packageManager = pm
+	}
+	if cfg.Backend != "" {
+		backend, err := parseBackend(cfg.Backend)
+		if err != nil {
+			return err
+		}
+		m.backend = backend
+	}
+	if cfg.ConfigFormat != "" {
+		format, err := parseConfigFormat(cfg.ConfigFormat)
+		if err != nil {
+			return err
+		}
+		m.configFormat = format
+	}
+	if cfg.PhpstanLevel != nil {
+		m.phpstanLevel = *cfg.PhpstanLevel
+	}
+	if cfg.LicenseTemplate != "" {
+		m.licenseTemplate = cfg.LicenseTemplate
+	}
+	if cfg.JiraKey != "" {
+		m.jiraKey = cfg.JiraKey
+	}
+	for tool, extensions := range cfg.Extensions {
+		if !slices.Contains(extensionTools(), tool) {
+			return fmt.Errorf("unknown tool %q in extensions", tool)
+		}
+		if m.extensions == nil {
+			m.extensions = map[string][]string{}
+		}
+		m.extensions[tool] = extensions
+	}
+	if cfg.Pin != nil {
+		m.pin = *cfg.Pin
+	}
+	if cfg.LintStagedFunctions != nil {
+		m.lintStagedFunctions = *cfg.LintStagedFunctions
+	}
+	if cfg.SkipCI != nil {
+		m.skipCI = *cfg.SkipCI
+	}
+	if cfg.ConfigDir != "" {
+		m.configDir = parseConfigDir(cfg.ConfigDir)
+	}
+	if cfg.PhpcsStandard != "" {
+		standard, err := parsePhpcsStandard(cfg.PhpcsStandard)
+		if err != nil {
+			return err
+		}
+		m.phpcsStandard = standard
+	}
+	if len(cfg.ProtectedBranches) > 0 {
+		m.protectedBranches = cfg.ProtectedBranches
+	}
+	if cfg.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be 1 or more, got %d", cfg.Concurrency)
+	}
+	if cfg.Concurrency > 0 {
+		m.concurrency = cfg.Concurrency
+	}
+	if cfg.NodeVersion != "" {
+		version, err := parseNodeVersion(cfg.NodeVersion)
+		if err != nil {
+			return err
+		}
+		m.nodeVersion = version
+	}
+	for pkg, version := range cfg.Versions {
+		if m.versions == nil {
+			m.versions = map[string]string{}
+		}
+		m.versions[pkg] = version
+	}
+	if len(cfg.PrePush) > 0 {
+		prePush, err := parsePrePush(cfg.PrePush)
+		if err != nil {
+			return err
+		}
+		m.prePush = prePush
+	}
+	for name, enabled := range cfg.Tools {
+		if _, ok := findTool(name); !ok {
+			return fmt.Errorf("unknown tool %q", name)
+		}
+		m.enabled[name] = enabled
+	}
+	return nil
+}
+
+func saveConfig(m model, path string) error {
+	cfg := fileConfig{
+		Docroots:            m.docroots,
+		PackageManager:      string(m.packageManager),
+		Backend:             m.backend,
+		ConfigFormat:        string(m.configFormat),
+		Tools:               map[string]bool{},
+		Extensions:          m.extensions,
+		Pin:                 &m.pin,
+		LintStagedFunctions: &m.lintStagedFunctions,
+		SkipCI:              &m.skipCI,
+		ConfigDir:           m.configDir,
+		Versions:            m.versions,
+		Concurrency:         m.concurrency,
+		NodeVersion:         m.nodeVersion,
+	}
+	if m.preset != "none" {
+		cfg.Preset = m.preset
+	}
+	if m.enabled["phpstan"] {
+		cfg.PhpstanLevel = &m.phpstanLevel
+	}
+	if m.enabled["license-header"] {
+		cfg.LicenseTemplate = m.licenseTemplate
+	}
+	if m.enabled["jira-prepare-commit-msg"] {
+		cfg.JiraKey = m.jiraKey
+	}
+	if m.enabled["phpcs"] {
+		cfg.PhpcsStandard = m.phpcsStandard
+	}
+	if m.enabled["protect-branches"] {
+		cfg.ProtectedBranches = m.protectedBranches
+	}
+	for _, t := range toolRegistry() {
+		cfg.Tools[t.name] = m.enabled[t.name]
+	}
+	for _, tool := range prePushTools() {
+		if m.prePush[tool] {
+			cfg.PrePush = append(cfg.PrePush, tool)
+		}
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
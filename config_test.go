@@ -0,0 +1,169 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	inTempDir(t)
+	if err := os.Mkdir("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, configFileName, `docroot: web
+packageManager: pnpm
+tools:
+  eslint: true
+  phpcs: true
+`)
+	m, err := loadConfig(configFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.docroots, []string{"web"}) || m.packageManager != pnpm || !m.enabled["eslint"] || !m.enabled["phpcs"] || m.enabled["prettier"] {
+		t.Errorf("loadConfig: docroots=%v pm=%s eslint=%v phpcs=%v prettier=%v", m.docroots, m.packageManager, m.enabled["eslint"], m.enabled["phpcs"], m.enabled["prettier"])
+	}
+
+	m, nonInteractive := loadTestModel(t)
+	if !nonInteractive {
+		t.Fatalf("the config file does not skip the wizard")
+	}
+	if !slices.Equal(m.docroots, []string{"web"}) || m.packageManager != pnpm || !m.enabled["eslint"] || !m.enabled["phpcs"] || m.enabled["prettier"] {
+		t.Errorf("docroots=%v pm=%s eslint=%v phpcs=%v prettier=%v", m.docroots, m.packageManager, m.enabled["eslint"], m.enabled["phpcs"], m.enabled["prettier"])
+	}
+
+	if _, nonInteractive := loadTestModel(t, "-interactive"); nonInteractive {
+		t.Errorf("-interactive does not start the wizard")
+	}
+}
+
+func TestConfigErrorsNameTheFile(t *testing.T) {
+	inTempDir(t)
+	for content, want := range map[string]string{
+		"tools:\n  eslnt: true\n": `unknown tool "eslnt"`,
+		"packageManager: pip\n":   `unknown package manager "pip"`,
+		"docroots: web\n":         "parsing",
+		"colour: blue\n":          "field colour not found",
+	} {
+		writeConfig(t, configFileName, content)
+		_, err := loadConfig(configFileName)
+		if err == nil || !strings.Contains(err.Error(), configFileName) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %v, want one naming the file and %q", content, err, want)
+		}
+	}
+}
+
+func TestSaveConfigRoundTrip(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.docroots = []string{"apps/web", "apps/admin"}
+	m.packageManager = yarn
+	m.configFormat = formatJSON
+	m.enabled["eslint"], m.enabled["phpcs"], m.enabled["commitlint"] = true, true, true
+	m.phpcsStandard = "psr12"
+	m.enabled["jira-prepare-commit-msg"], m.jiraKey = true, "ABC"
+	m.extensions = map[string][]string{"eslint": {"js", "mjs"}}
+	m.prePush = map[string]bool{"eslint": true}
+	m.pin = true
+	m.concurrency = 2
+	m.nodeVersion = "20"
+	if err := saveConfig(m, configFileName); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := readConfig(configFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := initialModel()
+	if err := cfg.apply(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(selectedTools(loaded), selectedTools(m)) {
+		t.Errorf("tools = %v, want %v", selectedTools(loaded), selectedTools(m))
+	}
+	if !slices.Equal(loaded.docroots, m.docroots) || loaded.packageManager != yarn || loaded.configFormat != formatJSON {
+		t.Errorf("docroots=%v pm=%s format=%s", loaded.docroots, loaded.packageManager, loaded.configFormat)
+	}
+	if loaded.phpcsStandard != "psr12" || loaded.jiraKey != "ABC" || !loaded.pin || loaded.concurrency != 2 || loaded.nodeVersion != "20" {
+		t.Errorf("phpcsStandard=%s jiraKey=%s pin=%v concurrency=%d nodeVersion=%s", loaded.phpcsStandard, loaded.jiraKey, loaded.pin, loaded.concurrency, loaded.nodeVersion)
+	}
+	if !slices.Equal(loaded.extensions["eslint"], []string{"js", "mjs"}) || !loaded.prePush["eslint"] {
+		t.Errorf("extensions=%v prePush=%v", loaded.extensions, loaded.prePush)
+	}
+}
+
+func TestMergeConfigsPrecedence(t *testing.T) {
+	yes, no := true, false
+	global := fileConfig{
+		PackageManager:      "pnpm",
+		JiraKey:             "ACME",
+		Tools:               map[string]bool{"eslint": true, "prettier": true},
+		Extensions:          map[string][]string{"eslint": {"js", "mjs"}},
+		LintStagedFunctions: &yes,
+	}
+	repo := fileConfig{
+		Docroot:             "web",
+		PackageManager:      "yarn",
+		Tools:               map[string]bool{"prettier": false, "phpcs": true},
+		LintStagedFunctions: &no,
+	}
+	merged := mergeConfigs(global, repo)
+	if merged.PackageManager != "yarn" || merged.JiraKey != "ACME" || merged.Docroot != "web" {
+		t.Errorf("packageManager=%q jiraKey=%q docroot=%q", merged.PackageManager, merged.JiraKey, merged.Docroot)
+	}
+	want := map[string]bool{"eslint": true, "prettier": false, "phpcs": true}
+	if len(merged.Tools) != len(want) {
+		t.Errorf("tools = %v, want %v", merged.Tools, want)
+	}
+	for tool, enabled := range want {
+		if merged.Tools[tool] != enabled {
+			t.Errorf("tools = %v, want %v", merged.Tools, want)
+		}
+	}
+	if !slices.Equal(merged.Extensions["eslint"], []string{"js", "mjs"}) {
+		t.Errorf("extensions = %v", merged.Extensions)
+	}
+	if merged.LintStagedFunctions == nil || *merged.LintStagedFunctions {
+		t.Errorf("the repository config does not turn lintStagedFunctions off")
+	}
+	if merged := mergeConfigs(repo, fileConfig{}); merged.LintStagedFunctions == nil || merged.PackageManager != "yarn" {
+		t.Errorf("an empty config replaces values: %+v", merged)
+	}
+}
+
+func TestGlobalRepoAndFlagPrecedence(t *testing.T) {
+	inTempDir(t)
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "pre-committer"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(home, "pre-committer", "config.yml"), "packageManager: pnpm\njiraKey: ACME\ntools:\n  eslint: true\n  prettier: true\n")
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	// The global config alone only provides the wizard's defaults.
+	m, nonInteractive := loadModel(nil)
+	if nonInteractive || m.packageManager != pnpm || m.jiraKey != "ACME" || !m.enabled["eslint"] || !m.enabled["prettier"] {
+		t.Errorf("global only: nonInteractive=%v pm=%s jiraKey=%q eslint=%v prettier=%v", nonInteractive, m.packageManager, m.jiraKey, m.enabled["eslint"], m.enabled["prettier"])
+	}
+
+	writeConfig(t, configFileName, "docroot: .\npackageManager: yarn\ntools:\n  prettier: false\n")
+	m, _ = loadModel(nil)
+	if m.packageManager != yarn || m.jiraKey != "ACME" || !m.enabled["eslint"] || m.enabled["prettier"] {
+		t.Errorf("repository over global: pm=%s jiraKey=%q eslint=%v prettier=%v", m.packageManager, m.jiraKey, m.enabled["eslint"], m.enabled["prettier"])
+	}
+
+	m, _ = loadModel([]string{"-pm", "npm", "-prettier", "-jira-key", "WEB"})
+	if m.packageManager != npm || m.jiraKey != "WEB" || !m.enabled["eslint"] || !m.enabled["prettier"] {
+		t.Errorf("flags over configs: pm=%s jiraKey=%q eslint=%v prettier=%v", m.packageManager, m.jiraKey, m.enabled["eslint"], m.enabled["prettier"])
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// toolConfig is a config file -config-dir can move, with the option that
+// points its tool at the new location. The tools of toolRegistry carry their
+// own.
+type toolConfig struct {
+	flag string
+	name func(m model) string
+}
+
+func fixedName(name string) func(m model) string {
+	return func(model) string { return name }
+}
+
+func formatName(jsName, jsonName string) func(m model) string {
+	return func(m model) string { return m.configName(jsName, jsonName) }
+}
+
+// toolConfigs are the configs of what is set up for every tool.
+var toolConfigs = map[string]toolConfig{
+	"lint-staged": {"--config", formatName(".lintstagedrc.js", ".lintstagedrc.json")},
+}
+
+// rootConfigs are the config files that stay in the project root with
+// -config-dir, and why.
+var rootConfigs = map[string]string{
+	"biome.json":                   "Biome resolves .gitignore relative to it",
+	"cspell.json":                  "cspell resolves its ignore paths relative to it",
+	".rubocop.yml":                 "RuboCop resolves its excludes relative to it",
+	".secretlintignore":            "secretlint looks for it in the project root",
+	".validate-branch-namerc.js":   "validate-branch-name has no option to find it elsewhere",
+	".validate-branch-namerc.json": "validate-branch-name has no option to find it elsewhere",
+	".jirapreparecommitmsgrc":      "jira-prepare-commit-msg has no option to find it elsewhere",
+	"tsconfig.json":                "editors and tsc look for it in the project root",
+	"setup.cfg":                    "Python tools look for it in the project root",
+	".editorconfig":                "editors look for it in the project root",
+	".nvmrc":                       "nvm looks for it in the project root",
+	".pre-commit-config.yaml":      "pre-commit looks for it in the project root",
+}
+
+// configFile is where the config of lint-staged or of a tool is written.
+// The configs of rootConfigs stay in the project root.
+func (m model) configFile(tool string) string {
+	config := toolConfig{}
+	if c, ok := toolConfigs[tool]; ok {
+		config = c
+	} else if t, ok := findTool(tool); ok {
+		config = t.config
+	}
+	if config.name == nil {
+		return ""
+	}
+	name := config.name(m)
+	if _, ok := rootConfigs[name]; ok {
+		return name
+	}
+	return m.configPath(name)
+}
+
+func configFlag(tool string) string {
+	if config, ok := toolConfigs[tool]; ok {
+		return config.flag
+	}
+	t, _ := findTool(tool)
+	return t.config.flag
+}
+
+func (m model) configPath(name string) string {
+	if m.configDir == "" {
+		return name
+	}
+	return filepath.ToSlash(filepath.Join(m.configDir, name))
+}
+
+// withConfig points command at the tool's config when it lives in the config
+// dir. Configs that stay in the root need no option. Without -config-dir the
+// tools find their configs themselves, and the commands stay as short as
+// before.
+func (m model) withConfig(tool, command string) string {
+	flag := configFlag(tool)
+	if m.configDir == "" || flag == "" {
+		return command
+	}
+	return command + " " + flag + " " + m.configFile(tool)
+}
+
+// fromConfigDir makes a project path relative to the config dir, for configs
+// whose paths are resolved relative to themselves.
+func (m model) fromConfigDir(path string) string {
+	if m.configDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(m.configDir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func parseConfigDir(value string) string {
+	dir := cleanDocroot(strings.TrimSpace(value))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type configFormat string
+
+const (
+	formatJS   configFormat = "js"
+	formatJSON configFormat = "json"
+)
+
+func parseConfigFormat(value string) (configFormat, error) {
+	switch format := configFormat(value); format {
+	case formatJS, formatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown config format %q (use js or json)", value)
+}
+
+// object is a config object that keeps its keys in insertion order, so the
+// generated files read in a natural order instead of alphabetically.
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+func (o object) get(key string) (any, bool) {
+	for _, f := range o {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces the value of key, keeping its position, or appends it.
+func (o object) set(key string, value any) object {
+	for i, f := range o {
+		if f.key == key {
+			o[i].value = value
+			return o
+		}
+	}
+	return append(o, field{key, value})
+}
+
+func (o object) remove(key string) object {
+	var kept object
+	for _, f := range o {
+		if f.key != key {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := marshalJSON(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSON(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// parseJSONObject decodes a JSON object into an object, keeping the key order
+// so that files such as package.json can be rewritten without reshuffling.
+func parseJSONObject(data []byte) (object, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := value.(object)
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	return config, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		config := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			config = append(config, field{key.(string), value})
+		}
+		_, err := decoder.Token()
+		return config, err
+	case '[':
+		list := []any{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := decoder.Token()
+		return list, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// configName picks the file name matching the configured format.
+func (m model) configName(jsName, jsonName string) string {
+	if m.configFormat == formatJSON {
+		return jsonName
+	}
+	return jsName
+}
+
+func (m model) renderConfig(config object) string {
+	if m.configFormat == formatJSON {
+		return renderJSON(config)
+	}
+	return renderJS(config)
+}
+
+func renderJSON(config object) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		// Config objects only hold strings, numbers, booleans, lists and
+		// nested objects, which always marshal.
+		panic(err)
+	}
+	return b.String()
+}
+
+// marshalJSON is json.Marshal without escaping <, > and &, which show up in
+// shell commands.
+func marshalJSON(value any) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+func renderJS(config object) string {
+	return "module.exports = " + jsValue(config, "") + ";\n"
+}
+
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func jsValue(value any, indent string) string {
+	switch v := value.(type) {
+	case string:
+		return jsString(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = jsString(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case []object:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range v {
+			b.WriteString(indent + "  " + jsValue(item, indent+"  ") + ",\n")
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	case filesFunction:
+		templates := make([]string, len(v))
+		for i, command := range v {
+			templates[i] = "`" + escapeTemplate(command) + " " + filesArgument + "`"
+		}
+		return "(files) => [" + strings.Join(templates, ", ") + "]"
+	case object:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, f := range v {
+			key := f.key
+			if !jsIdentifier.MatchString(key) {
+				key = jsString(key)
+			}
+			b.WriteString(indent + "  " + key + ": " + jsValue(f.value, indent+"  ") + ",\n")
+		}
+		b.WriteString(indent + "}")
+		return b.String()
+	}
+	return fmt.Sprint(value)
+}
+
+func jsString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+// escapeTemplate escapes s for use inside a template literal.
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "`", "\\`")
+	return strings.ReplaceAll(s, "${", `\${`)
+}
+
+// parseJSConfig reads back a config written by renderJS. It understands the
+// subset of JavaScript renderJS emits: objects, arrays, quoted strings,
+// numbers, booleans and lint-staged task functions.
+func parseJSConfig(src string) (object, error) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(src), "module.exports = ")
+	if !ok {
+		return nil, fmt.Errorf("expected module.exports = ...")
+	}
+	p := &jsParser{src: strings.TrimSuffix(body, ";")}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	config, ok := value.(object)
+	if !ok {
+		return nil, fmt.Errorf("expected an object")
+	}
+	return config, nil
+}
+
+type jsParser struct {
+	src string
+	pos int
+}
+
+func (p *jsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jsParser) skipSpace() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips c, and the space before it, if it comes next.
+func (p *jsParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+var jsLiteral = regexp.MustCompile(`^(true|false|-?[0-9]+(\.[0-9]+)?)`)
+
+func (p *jsParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		p.pos++
+		return p.object()
+	case c == '[':
+		p.pos++
+		return p.array()
+	case c == '\'' || c == '"':
+		return p.string()
+	case strings.HasPrefix(p.src[p.pos:], filesFunctionPrefix):
+		p.pos += len(filesFunctionPrefix)
+		return p.filesFunction()
+	}
+	literal := jsLiteral.FindString(p.src[p.pos:])
+	if literal == "" {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	p.pos += len(literal)
+	if literal == "true" || literal == "false" {
+		return literal == "true", nil
+	}
+	return json.Number(literal), nil
+}
+
+var jsKey = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+
+func (p *jsParser) object() (object, error) {
+	config := object{}
+	for !p.consume('}') {
+		p.skipSpace()
+		var key string
+		if p.pos < len(p.src) && (p.src[p.pos] == '\'' || p.src[p.pos] == '"') {
+			quoted, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			key = quoted
+		} else if key = jsKey.FindString(p.src[p.pos:]); key == "" {
+			return nil, p.errorf("expected a key")
+		} else {
+			p.pos += len(key)
+		}
+		if !p.consume(':') {
+			return nil, p.errorf("expected : after %q", key)
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		config = append(config, field{key, value})
+		if p.consume('}') {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected , or }")
+		}
+	}
+	return config, nil
+}
+
+func (p *jsParser) array() ([]any, error) {
+	list := []any{}
+	for !p.consume(']') {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if p.consume(']') {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+	return list, nil
+}
+
+const filesFunctionPrefix = "(files) => ["
+
+func (p *jsParser) filesFunction() (filesFunction, error) {
+	var commands filesFunction
+	for !p.consume(']') {
+		if p.skipSpace(); p.pos == len(p.src) || p.src[p.pos] != '`' {
+			return nil, p.errorf("expected a template literal")
+		}
+		template, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		command, ok := strings.CutSuffix(template, " "+filesArgument)
+		if !ok {
+			return nil, p.errorf("expected the command to end with the file names")
+		}
+		commands = append(commands, command)
+		if p.consume(']') {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+	return commands, nil
+}
+
+func (p *jsParser) string() (string, error) {
+	quote := p.src[p.pos]
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case quote:
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if p.pos++; p.pos == len(p.src) {
+				return "", p.errorf("unterminated string")
+			}
+			b.WriteByte(p.src[p.pos])
+		case '\n':
+			return "", p.errorf("unterminated string")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBothFormatsFromTheSameModel(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"] = true, true
+	js := m
+	js.configFormat = formatJS
+	asJSON := m
+	asJSON.configFormat = formatJSON
+
+	for tool, names := range map[string][2]string{
+		"eslint":      {".eslintrc.js", ".eslintrc.json"},
+		"prettier":    {".prettierrc.js", ".prettierrc"},
+		"lint-staged": {".lintstagedrc.js", ".lintstagedrc.json"},
+	} {
+		if got := js.configFile(tool); got != names[0] {
+			t.Errorf("js %s config = %s, want %s", tool, got, names[0])
+		}
+		if got := asJSON.configFile(tool); got != names[1] {
+			t.Errorf("json %s config = %s, want %s", tool, got, names[1])
+		}
+	}
+
+	// Both renderings hold the same data.
+	for name, config := range map[string]object{"eslint": eslintConfig(m), "prettier": prettierConfig(m), "lint-staged": lintStagedConfig(m)} {
+		jsSource := js.renderConfig(config)
+		if !strings.HasPrefix(jsSource, "module.exports = {") {
+			t.Errorf("%s js config starts with %q", name, jsSource[:20])
+		}
+		fromJS, err := parseJSConfig(jsSource)
+		if err != nil {
+			t.Fatalf("%s js config does not parse: %v", name, err)
+		}
+		var fromJSON, want any
+		if err := json.Unmarshal([]byte(asJSON.renderConfig(config)), &fromJSON); err != nil {
+			t.Fatalf("%s json config does not parse: %v", name, err)
+		}
+		data, _ := json.Marshal(fromJS)
+		if err := json.Unmarshal(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fromJSON, want) {
+			t.Errorf("%s: js and json configs differ:\n%v\n%v", name, want, fromJSON)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func newFlagSet(m *model) *flag.FlagSet {
+	fs := flag.NewFlagSet("pre-committer", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: pre-committer [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Without flags an interactive wizard is started. When any flag is given, or a\n")
+		fmt.Fprintf(fs.Output(), "%s file exists, the wizard is skipped and the hooks are set up from\n", configFileName)
+		fmt.Fprintf(fs.Output(), "the file and the flags. Flags override the file.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Func("preset", "pre-select tools from a preset: "+strings.Join(presetNames(), ", ")+"; later flags override it", func(value string) error {
+		return applyPreset(m, value)
+	})
+	fs.Func("docroot", "path of your docroot, or comma-separated paths in a monorepo (auto-detected when empty)", func(value string) error {
+		m.docroots = parseDocroots(value)
+		return nil
+	})
+	fs.Func("docroot-candidates", "comma-separated folders to look for when auto-detecting the docroot (default "+strings.Join(defaultDocrootCandidates, ",")+")", func(value string) error {
+		m.docrootCandidates = parseDocroots(value)
+		return nil
+	})
+	for _, t := range toolRegistry() {
+		fs.BoolFunc(t.name, t.usage, func(value string) error {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			m.enabled[t.name] = enabled
+			return nil
+		})
+	}
+	for _, tool := range extensionTools() {
+		fs.Func(tool+"-ext", "comma-separated file extensions "+tool+" checks, e.g. js,mjs,cjs", func(value string) error {
+			if m.extensions == nil {
+				m.extensions = map[string][]string{}
+			}
+			m.extensions[tool] = parseExtensions(value)
+			return nil
+		})
+	}
+	fs.Func("pre-push", "comma-separated tools to run on the whole project before pushing instead of on staged files: "+strings.Join(prePushTools(), ", "), func(value string) error {
+		prePush, err := parsePrePush(parseExtensions(value))
+		if err != nil {
+			return err
+		}
+		m.prePush = prePush
+		return nil
+	})
+	fs.BoolVar(&m.pin, "pin", m.pin, "install tested, exact versions of the packages instead of the latest")
+	fs.Func("version", "install package=version, e.g. eslint=8.57.0, instead of the latest or pinned version; can be repeated", func(value string) error {
+		pkg, version, err := parseVersion(value)
+		if err != nil {
+			return err
+		}
+		if m.versions == nil {
+			m.versions = map[string]string{}
+		}
+		m.versions[pkg] = version
+		return nil
+	})
+	fs.Func("phpcs-standard", "coding standard PHPCS checks: "+strings.Join(phpcsStandardNames, ", ")+" (default drupal)", func(value string) error {
+		standard, err := parsePhpcsStandard(value)
+		if err != nil {
+			return err
+		}
+		m.phpcsStandard = standard
+		return nil
+	})
+	fs.Func("protected-branches", "comma-separated branches -protect-branches rejects commits to (default "+strings.Join(defaultProtectedBranches, ",")+")", func(value string) error {
+		branches := parseBranches(value)
+		if len(branches) == 0 {
+			return fmt.Errorf("no branches given")
+		}
+		m.protectedBranches = branches
+		return nil
+	})
+	fs.Func("node-version", "Node version to write to .nvmrc and engines.node in package.json, e.g. 20", func(value string) error {
+		version, err := parseNodeVersion(value)
+		if err != nil {
+			return err
+		}
+		m.nodeVersion = version
+		return nil
+	})
+	fs.IntVar(&m.phpstanLevel, "phpstan-level", m.phpstanLevel, "PHPStan rule level from 0 to 9")
+	fs.StringVar(&m.licenseTemplate, "license-template", m.licenseTemplate, "path of the license header template; a starter is written when it does not exist")
+	fs.StringVar(&m.jiraKey, "jira-key", m.jiraKey, "JIRA project key, e.g. ABC, or a regex matching ticket numbers, for -jira-prepare-commit-msg")
+	fs.Func("pm", "package manager: npm, pnpm, yarn, yarn-berry or bun (auto-detected when empty)", func(value string) error {
+		pm, err := parsePackageManager(value)
+		if err != nil {
+			return err
+		}
+		m.packageManager = pm
+		return nil
+	})
+	fs.BoolFunc("no-husky", "run lint-staged from hooks in .git/hooks instead of husky, like -backend git", func(value string) error {
+		noHusky, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if noHusky {
+			m.backend = backendGit
+		}
+		return nil
+	})
+	fs.Func("backend", "hook manager: husky (default), pre-commit, or git for plain hooks in .git/hooks", func(value string) error {
+		backend, err := parseBackend(value)
+		if err != nil {
+			return err
+		}
+		m.backend = backend
+		return nil
+	})
+	fs.BoolVar(&m.dryRun, "dry-run", m.dryRun, "print the files that would be written and the commands that would run without doing it")
+	fs.Func("config-format", "format of generated config files: js or json", func(value string) error {
+		format, err := parseConfigFormat(value)
+		if err != nil {
+			return err
+		}
+		m.configFormat = format
+		return nil
+	})
+	fs.Func("config-dir", "directory to write the tools' config files to instead of the project root", func(value string) error {
+		m.configDir = parseConfigDir(value)
+		return nil
+	})
+	fs.BoolVar(&m.lintStagedFunctions, "lintstaged-functions", m.lintStagedFunctions, "write lint-staged tasks as functions that pass the file names explicitly (js config format only)")
+	fs.Func("concurrency", "number of lint-staged tasks to run at once, 1 to run them one after another (default all at once)", func(value string) error {
+		concurrency, err := parseConcurrency(value)
+		if err != nil {
+			return err
+		}
+		m.concurrency = concurrency
+		return nil
+	})
+	fs.BoolVar(&m.skipCI, "skip-ci", m.skipCI, "skip the hooks when CI=true, e.g. for commits made by CI jobs (husky and git backends)")
+	fs.BoolVar(&m.verify, "verify", m.verify, "run lint-staged once after setup to check that the hook works")
+	fs.BoolVar(&m.force, "force", m.force, "overwrite existing config files")
+	fs.BoolVar(&m.uninstall, "uninstall", m.uninstall, "remove the hooks, config files and packages an earlier setup added")
+	fs.BoolVar(&m.quiet, "quiet", m.quiet, "only print errors")
+	fs.BoolVar(&m.verbose, "verbose", m.verbose, "log every file written and command run to stderr")
+	fs.BoolVar(&m.profile, "profile", m.profile, "print how long each phase of the setup took")
+	fs.BoolVar(&m.interactive, "interactive", m.interactive, "start the wizard even when flags or "+configFileName+" are given")
+	fs.BoolVar(&m.listTools, "list-tools", m.listTools, "list the supported tools, the files they check and the packages they install, and exit")
+	fs.BoolVar(&m.yes, "yes", m.yes, "assume yes for all prompts and run without the wizard")
+	return fs
+}
+
+// loadModel builds the model from .pre-committer.yml, when present, and the
+// command line flags, which take precedence. It reports whether the wizard can
+// be skipped.
+func loadModel(args []string) (model, bool) {
+	m := initialModel()
+	var configs []fileConfig
+	fromFile := false
+	if path, err := globalConfigPath(); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			configs = append(configs, mustReadConfig(path))
+		}
+	}
+	if _, err := os.Stat(configFileName); err == nil {
+		configs = append(configs, mustReadConfig(configFileName))
+		fromFile = true
+	}
+	cfg := mergeConfigs(configs...)
+	// Each config was checked when read, so the merged values are valid.
+	_ = cfg.apply(&m)
+	fs := newFlagSet(&m)
+	// ExitOnError makes Parse exit on invalid flags, so the error is always nil.
+	_ = fs.Parse(args)
+	if m.profile {
+		m.timings = &phaseTimings{}
+	}
+	if m.listTools {
+		if err := listTools(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing tools: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if m.interactive || (fs.NFlag() == 0 && !fromFile) {
+		if !fromFile {
+			m.applyQuestionDefaults(fs, cfg)
+		} else {
+			// The file lists every tool, so the wizard keeps them all.
+			m.presetBase = map[string]bool{}
+			for _, t := range toolRegistry() {
+				m.presetBase[t.name] = m.enabled[t.name]
+			}
+		}
+		m.presetCursor = max(slices.Index(presetNames(), m.preset), 0)
+		m.docrootInput = strings.Join(m.docroots, ",")
+		m.branchesInput = strings.Join(m.protectedBranches, ",")
+		m.detectedNode = detectNodeVersion()
+		return m, false
+	}
+	if len(m.docroots) == 0 {
+		m.docroots = []string{detectDocroot(m.docrootCandidates)}
+		m.infof("Auto-detected docroot: %s\n", m.docroots[0])
+	} else if missing := missingPath(m.docroots); missing != "" {
+		fmt.Fprintf(os.Stderr, "Docroot %s not found\n", missing)
+		os.Exit(1)
+	}
+	return m.withoutReplacedTools(), true
+}
+
+func parseConcurrency(value string) (int, error) {
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		return 0, fmt.Errorf("concurrency must be a number of 1 or more, got %q", value)
+	}
+	return concurrency, nil
+}
+
+func mustReadConfig(path string) fileConfig {
+	cfg, err := readConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// applyQuestionDefaults starts the wizard's tools at their default
+// selection, except for tools already chosen with a flag or in the global
+// config, which are kept as the wizard's presetBase. A preset replaces the
+// defaults altogether.
+func (m *model) applyQuestionDefaults(fs *flag.FlagSet, global fileConfig) {
+	fromFlags := map[string]bool{}
+	preset := global.Preset != ""
+	fs.Visit(func(f *flag.Flag) {
+		fromFlags[f.Name] = true
+		preset = preset || f.Name == "preset"
+	})
+	m.presetBase = map[string]bool{}
+	for _, t := range toolRegistry() {
+		if _, ok := global.Tools[t.name]; ok || fromFlags[t.name] {
+			m.presetBase[t.name] = m.enabled[t.name]
+		} else if !preset {
+			m.enabled[t.name] = t.preselected
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadTestModel runs loadModel in an empty directory with no global config.
+func loadTestModel(t *testing.T, args ...string) (model, bool) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return loadModel(args)
+}
+
+func TestFlagsBuildTheModel(t *testing.T) {
+	inTempDir(t)
+	if err := os.Mkdir("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	m, nonInteractive := loadTestModel(t, "-eslint", "-prettier", "-docroot", "web", "-yes")
+	if !nonInteractive {
+		t.Fatalf("flags do not skip the wizard")
+	}
+	if !m.enabled["eslint"] || !m.enabled["prettier"] || !m.yes || m.enabled["stylelint"] || m.enabled["phpcs"] {
+		t.Errorf("eslint=%v prettier=%v yes=%v stylelint=%v phpcs=%v", m.enabled["eslint"], m.enabled["prettier"], m.yes, m.enabled["stylelint"], m.enabled["phpcs"])
+	}
+	if !slices.Equal(m.docroots, []string{"web"}) {
+		t.Errorf("docroots = %v", m.docroots)
+	}
+
+	if _, nonInteractive := loadTestModel(t); nonInteractive {
+		t.Errorf("no flags and no config skip the wizard")
+	}
+}
+
+func TestUsageListsTheFlags(t *testing.T) {
+	m := initialModel()
+	fs := newFlagSet(&m)
+	var b bytes.Buffer
+	fs.SetOutput(&b)
+	fs.Usage()
+	for _, want := range []string{"Usage: pre-committer [flags]", "-eslint", "-docroot", "-yes", "-dry-run"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("usage does not mention %s", want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+const licenseHeaderScript = ".license-header.cjs"
+
+var licenseHeaderExtensions = []string{"js", "jsx", "ts", "tsx", "css", "scss", "php", "go", "py", "sh"}
+
+const licenseTemplateStarter = `Copyright (c) <year> <company>. All rights reserved.
+`
+
+// licenseHeaderCheck only reports files without the header. lint-staged runs
+// the globs concurrently, so rewriting files here could race with the
+// formatters; --fix adds the header when run by hand.
+const licenseHeaderCheck = `#!/usr/bin/env node
+// Checks that each file starts with the license header from the template.
+// Usage: node .license-header.cjs [--fix] <template> <files...>
+const fs = require('fs');
+const path = require('path');
+
+const args = process.argv.slice(2);
+const fix = args[0] === '--fix';
+const [template, ...files] = fix ? args.slice(1) : args;
+const text = fs.readFileSync(template, 'utf8').trimEnd();
+const hashComments = new Set(['.py', '.sh', '.rb', '.yml', '.yaml']);
+
+function header(file) {
+  const lines = text.split('\n');
+  if (hashComments.has(path.extname(file))) {
+    return lines.map((line) => ('# ' + line).trimEnd()).join('\n') + '\n';
+  }
+  return '/*\n' + lines.map((line) => (' * ' + line).trimEnd()).join('\n') + '\n */\n';
+}
+
+let missing = 0;
+for (const file of files) {
+  const content = fs.readFileSync(file, 'utf8');
+  const expected = header(file);
+  if (content.includes(expected)) {
+    continue;
+  }
+  if (!fix) {
+    console.error('Missing license header: ' + file);
+    missing++;
+    continue;
+  }
+  // Shebangs and the PHP opening tag have to stay on the first line.
+  const first = content.match(/^(#!.*|<\?php.*)\n/);
+  const prefix = first ? first[0] : '';
+  fs.writeFileSync(file, prefix + expected + '\n' + content.slice(prefix.length));
+  console.log('Added the license header to ' + file);
+}
+if (missing > 0) {
+  console.error('Add the headers with: node .license-header.cjs --fix ' + template + ' <files...>');
+  process.exit(1);
+}
+`
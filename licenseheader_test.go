@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLicenseHeaderCommand(t *testing.T) {
+	m := testModel()
+	m.enabled["license-header"] = true
+	glob := "*.{" + strings.Join(licenseHeaderExtensions, ",") + "}"
+	if got := commandsFor(t, m, glob); !slices.Equal(got, []string{"node .license-header.cjs license-header.txt"}) {
+		t.Errorf("%s runs %v", glob, got)
+	}
+	m.licenseTemplate = "legal/license header.txt"
+	if got := commandsFor(t, m, glob); !slices.Equal(got, []string{`node .license-header.cjs "legal/license header.txt"`}) {
+		t.Errorf("a template with a space runs %v", got)
+	}
+}
+
+func TestLicenseHeaderTemplate(t *testing.T) {
+	inTempDir(t)
+	stubLookPath(t)
+	m := testModel()
+	m.dryRun, m.yes = true, true
+	m.enabled["license-header"] = true
+	info := captureInfo(t)
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(info.String(), "would write license-header.txt") {
+		t.Errorf("no starter template for a missing one:\n%s", info.String())
+	}
+
+	writeConfig(t, "license-header.txt", "Copyright (c) 2024 Example.\n")
+	info.Reset()
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(info.String(), "license-header.txt") {
+		t.Errorf("an existing template is replaced:\n%s", info.String())
+	}
+}
+
+func TestLicenseHeaderScript(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	inTempDir(t)
+	writeConfig(t, licenseHeaderScript, licenseHeaderCheck)
+	writeConfig(t, "license.txt", "Copyright (c) 2024 Example.\n")
+	writeConfig(t, "with.js", "/*\n * Copyright (c) 2024 Example.\n */\nconsole.log(1);\n")
+	writeConfig(t, "without.py", "print(1)\n")
+	output, err := exec.Command("node", licenseHeaderScript, "license.txt", "with.js", "without.py").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "Missing license header: without.py") || strings.Contains(string(output), "with.js") {
+		t.Errorf("check: %v\n%s", err, output)
+	}
+	if output, err := exec.Command("node", licenseHeaderScript, "--fix", "license.txt", "without.py").CombinedOutput(); err != nil {
+		t.Fatalf("--fix: %v\n%s", err, output)
+	}
+	if data, _ := os.ReadFile("without.py"); string(data) != "# Copyright (c) 2024 Example.\n\nprint(1)\n" {
+		t.Errorf("--fix wrote %q", data)
+	}
+}
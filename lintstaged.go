@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func generateLintStagedConfig(m model) string {
+	config := lintStagedConfig(m)
+	if m.lintStagedFunctions && m.configFormat != formatJSON {
+		for i, f := range config {
+			config[i].value = filesFunction(f.value.([]string))
+		}
+	}
+	return m.renderConfig(config)
+}
+
+// filesFunction is a lint-staged task function that runs its commands with
+// the staged file names appended, rendered as
+//
+//	(files) => [`eslint --fix ${files.map(...).join(' ')}`]
+type filesFunction []string
+
+// filesArgument quotes each file name so that names with spaces survive
+// lint-staged splitting the command into arguments.
+const filesArgument = "${files.map((file) => JSON.stringify(file)).join(' ')}"
+
+// lintStagedConfig maps globs to the commands run on staged files. Tools
+// routed to pre-push are left out, as they check the whole project instead.
+func lintStagedConfig(m model) object {
+	commands := map[string][]string{}
+	addGlob := func(glob string, cmds ...string) {
+		commands[glob] = append(commands[glob], cmds...)
+	}
+	add := func(glob string, cmds ...string) {
+		for _, docroot := range m.docroots {
+			addGlob(scopeGlob(docroot, glob), cmds...)
+		}
+	}
+	for _, t := range m.enabledTools() {
+		if m.prePush[t.name] {
+			continue
+		}
+		for _, task := range m.lintStagedTasks(t) {
+			if t.unscoped {
+				addGlob(task.glob, task.commands...)
+			} else {
+				add(task.glob, task.commands...)
+			}
+		}
+	}
+	// Globs are sorted so the output stays the same however the tools are
+	// ordered. The commands of a glob keep their order, which matters.
+	globs := make([]string, 0, len(commands))
+	for glob := range commands {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+	config := object{}
+	for _, glob := range globs {
+		config = append(config, field{glob, commands[glob]})
+	}
+	return config
+}
+
+// phpcsCommands fix what phpcbf can and check the rest.
+func (m model) phpcsCommands() []string {
+	return []string{m.phpcbfCommand(), "vendor/bin/phpcs --standard=" + m.configFile("phpcs")}
+}
+
+// phpcbfCommand fixes what it can before phpcs checks the rest. phpcbf exits
+// with 1 when it fixed everything and 2 when errors are left, which phpcs then
+// reports, so only higher exit codes fail the commit.
+func (m model) phpcbfCommand() string {
+	return `sh -c 'vendor/bin/phpcbf --standard=` + m.configFile("phpcs") + ` "$@" || [ $? -le 2 ]' phpcbf`
+}
+
+// sqlFormatterCommand formats each file on its own, as sql-formatter takes a
+// single file. Its path is spelled out because the loop runs in a shell that
+// pre-commit does not give node_modules/.bin.
+func (m model) sqlFormatterCommand() string {
+	return `sh -c 'for f; do node_modules/.bin/sql-formatter --fix --config ` + m.configFile("sql-formatter") + ` "$f" || exit 1; done' sql-formatter`
+}
+
+// glob matches the files a tool checks: the extensions given for it with
+// -<tool>-ext, or defaults.
+func (m model) glob(tool string, defaults ...string) string {
+	extensions := defaults
+	if custom := m.extensions[tool]; len(custom) > 0 {
+		extensions = custom
+	}
+	return extensionsGlob(extensions)
+}
+
+func extensionsGlob(extensions []string) string {
+	if len(extensions) == 1 {
+		return "*." + extensions[0]
+	}
+	return "*.{" + strings.Join(extensions, ",") + "}"
+}
+
+func parseExtensions(value string) []string {
+	var extensions []string
+	for _, extension := range strings.Split(value, ",") {
+		extension = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(extension), "*"), ".")
+		if extension != "" {
+			extensions = append(extensions, extension)
+		}
+	}
+	return extensions
+}
+
+func scopeGlob(docroot, glob string) string {
+	if docroot == "" || docroot == "." {
+		return glob
+	}
+	return docroot + "/**/" + glob
+}
+
+// validateLintStagedConfig checks that a generated config parses and maps each
+// glob to a command, a list of commands or a function, so a generator bug
+// cannot leave the hook broken.
+func validateLintStagedConfig(content string) error {
+	var config object
+	var err error
+	if strings.HasPrefix(content, "module.exports") {
+		config, err = parseJSConfig(content)
+	} else {
+		config, err = parseJSONObject([]byte(content))
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range config {
+		switch value := f.value.(type) {
+		case string:
+			if value == "" {
+				return fmt.Errorf("%s: empty command", f.key)
+			}
+		case []any:
+			if len(value) == 0 {
+				return fmt.Errorf("%s: no commands", f.key)
+			}
+			for _, command := range value {
+				if command, ok := command.(string); !ok || command == "" {
+					return fmt.Errorf("%s: commands must be non-empty strings", f.key)
+				}
+			}
+		case filesFunction:
+			if len(value) == 0 {
+				return fmt.Errorf("%s: no commands", f.key)
+			}
+			for _, command := range value {
+				if command == "" {
+					return fmt.Errorf("%s: empty command", f.key)
+				}
+			}
+		default:
+			return fmt.Errorf("%s: expected a command, a list of commands or a function", f.key)
+		}
+	}
+	return nil
+}
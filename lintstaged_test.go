@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testModel returns a model with the docroot answered and no tools selected.
+// drupalGlob matches the files the default Drupal standard checks.
+const drupalGlob = "*.{php,module,inc,install,test,profile,theme,info,yml}"
+
+func testModel() model {
+	m := initialModel()
+	m.docroots = []string{"."}
+	return m
+}
+
+func TestSharedGlobHasOneEntry(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"] = true, true
+	config, err := parseJSConfig(generateLintStagedConfig(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config) != 1 || config[0].key != "*.js" {
+		t.Fatalf("config = %v, want a single *.js entry", config)
+	}
+	commands, _ := config[0].value.([]any)
+	if !slices.Equal(commands, []any{"eslint --fix", "prettier --write"}) {
+		t.Errorf("*.js runs %v, want eslint then prettier", commands)
+	}
+	if n := strings.Count(generateLintStagedConfig(m), "'*.js'"); n != 1 {
+		t.Errorf("*.js appears %d times in the generated config", n)
+	}
+}
+
+func TestGlobsAreScopedToTheDocroot(t *testing.T) {
+	tests := []struct {
+		docroots []string
+		want     []string
+	}{
+		{[]string{"."}, []string{"*.js"}},
+		{[]string{"web"}, []string{"web/**/*.js"}},
+		{[]string{"apps/site", "apps/admin"}, []string{"apps/admin/**/*.js", "apps/site/**/*.js"}},
+	}
+	for _, tt := range tests {
+		m := testModel()
+		m.docroots = tt.docroots
+		m.enabled["eslint"] = true
+		var globs []string
+		for _, f := range lintStagedConfig(m) {
+			globs = append(globs, f.key)
+		}
+		if !slices.Equal(globs, tt.want) {
+			t.Errorf("docroots %v: globs = %v, want %v", tt.docroots, globs, tt.want)
+		}
+	}
+}
+
+// commandsFor returns the commands the lint-staged config runs for glob.
+func commandsFor(t *testing.T, m model, glob string) []string {
+	t.Helper()
+	value, ok := lintStagedConfig(m).get(glob)
+	if !ok {
+		t.Fatalf("no %s entry in %v", glob, lintStagedConfig(m))
+	}
+	return value.([]string)
+}
+
+func TestGoGlob(t *testing.T) {
+	m := testModel()
+	m.enabled["go"] = true
+	if got := commandsFor(t, m, "*.go"); !slices.Equal(got, []string{"gofmt -w", "golangci-lint run"}) {
+		t.Errorf("*.go runs %v", got)
+	}
+	// The Go tools are binaries, not npm packages.
+	npm, _ := m.packageLists(gitHooksBackend{})
+	if !slices.Equal(npm, []string{"lint-staged"}) {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestPythonGlobAndConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["python"] = true
+	if got := commandsFor(t, m, "*.py"); !slices.Equal(got, []string{"black", "isort", "flake8"}) {
+		t.Errorf("*.py runs %v", got)
+	}
+	// flake8 has to allow black's line length and slice spacing.
+	for _, want := range []string{"[flake8]\nmax-line-length = 88\nextend-ignore = E203", "[isort]\nprofile = black"} {
+		if !strings.Contains(pythonSetupConfig, want) {
+			t.Errorf("setup.cfg does not contain %q", want)
+		}
+	}
+	if got := m.pythonRequirements(); !slices.Equal(got, []string{"black", "isort", "flake8"}) {
+		t.Errorf("requirements = %v", got)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "black") {
+		t.Errorf("pip tools are installed with npm: %v", npm)
+	}
+}
+
+func TestMarkdownlintGlobAndConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["markdownlint"] = true
+	if got := commandsFor(t, m, "*.md"); !slices.Equal(got, []string{"markdownlint --fix"}) {
+		t.Errorf("*.md runs %v", got)
+	}
+	var config map[string]any
+	if err := json.Unmarshal([]byte(markdownlintConfig), &config); err != nil {
+		t.Fatalf(".markdownlint.json does not parse: %v", err)
+	}
+	if config["default"] != true || config["MD013"] != false {
+		t.Errorf(".markdownlint.json = %v", config)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "markdownlint-cli") {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestEachDocrootGetsItsGlobs(t *testing.T) {
+	inTempDir(t)
+	for _, dir := range []string{"apps/web", "apps/admin"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := typeText(atQuestion(0), " apps/web/, ./apps/admin ")
+	m, _ = press(t, m, "enter")
+	if !slices.Equal(m.docroots, []string{"apps/web", "apps/admin"}) {
+		t.Fatalf("docroots = %v", m.docroots)
+	}
+	m.enabled["eslint"], m.enabled["stylelint"] = true, true
+	var globs []string
+	for _, f := range lintStagedConfig(m) {
+		globs = append(globs, f.key)
+	}
+	want := []string{"apps/admin/**/*.js", "apps/admin/**/*.{css,scss,sass}", "apps/web/**/*.js", "apps/web/**/*.{css,scss,sass}"}
+	if !slices.Equal(globs, want) {
+		t.Errorf("globs = %v, want %v", globs, want)
+	}
+}
+
+func TestStylelintCoversSCSS(t *testing.T) {
+	m := testModel()
+	m.enabled["stylelint"] = true
+	if got := commandsFor(t, m, "*.{css,scss,sass}"); !slices.Equal(got, []string{"stylelint --fix"}) {
+		t.Errorf("styles run %v", got)
+	}
+	extends, _ := stylelintConfig(m).get("extends")
+	if !slices.Equal(extends.([]string), []string{"stylelint-config-standard-scss"}) {
+		t.Errorf("extends = %v", extends)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "stylelint-config-standard-scss") {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestPrettierFormatsPHPBeforePHPCS(t *testing.T) {
+	m := testModel()
+	m.enabled["prettier"], m.enabled["phpcs"], m.enabled["prettier-php"] = true, true, true
+	// prettier shares the glob of the PHP code with PHPCS, so that they do
+	// not write the same files at once; the Drupal data files only get PHPCS.
+	code := "*.{php,module,inc,install,test,profile,theme}"
+	got := commandsFor(t, m, code)
+	if len(got) != 3 || got[0] != "prettier --write" || !strings.Contains(got[1], "phpcbf") || !strings.Contains(got[2], "vendor/bin/phpcs") {
+		t.Errorf("%s runs %v, want prettier before phpcbf and phpcs", code, got)
+	}
+	if got := commandsFor(t, m, "*.{info,yml}"); len(got) != 2 || slices.Contains(got, "prettier --write") {
+		t.Errorf("*.{info,yml} runs %v, want only phpcbf and phpcs", got)
+	}
+	plugins, _ := prettierConfig(m).get("plugins")
+	if !slices.Equal(plugins.([]string), []string{"@prettier/plugin-php"}) {
+		t.Errorf("plugins = %v", plugins)
+	}
+	overrides, _ := prettierConfig(m).get("overrides")
+	override := overrides.([]object)[0]
+	files, _ := override.get("files")
+	options, _ := override.get("options")
+	if parser, _ := options.(object).get("parser"); files != code || parser != "php" {
+		t.Errorf("prettier override files=%v options=%v", files, options)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "@prettier/plugin-php") {
+		t.Errorf("packages = %v", npm)
+	}
+
+	m.phpcsStandard = "psr12"
+	if got := commandsFor(t, m, "*.php"); len(got) != 3 || got[0] != "prettier --write" {
+		t.Errorf("PSR-12: *.php runs %v", got)
+	}
+
+	// Without PHPCS there is no standard for prettier to agree with.
+	m.enabled["phpcs"] = false
+	if _, ok := lintStagedConfig(m).get("*.php"); ok {
+		t.Errorf("prettier formats PHP without PHPCS")
+	}
+}
+
+func TestPhpcsGlobFollowsTheStandard(t *testing.T) {
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["phpcs"] = true
+	glob := "web/**/" + drupalGlob
+	commandsFor(t, m, glob)
+	matcher := regexp.MustCompile(globToRegexp(glob))
+	for _, name := range []string{"web/modules/custom/foo/foo.module", "web/modules/custom/foo/foo.install", "web/themes/custom/bar/bar.theme", "web/index.php"} {
+		if !matcher.MatchString(name) {
+			t.Errorf("%s does not match %s", name, glob)
+		}
+	}
+	if config := generatePhpcsConfig(m); !strings.Contains(config, `<arg name="extensions" value="php,module,inc,install,test,profile,theme,info,yml"/>`) {
+		t.Errorf("phpcs.xml does not list the standard's extensions:\n%s", config)
+	}
+
+	m.phpcsStandard = "wordpress"
+	commandsFor(t, m, "web/**/*.php")
+
+	// -phpcs-ext replaces the standard's extensions, also in phpcs.xml.
+	m.extensions = map[string][]string{"phpcs": {"php", "inc"}}
+	commandsFor(t, m, "web/**/*.{php,inc}")
+	if config := generatePhpcsConfig(m); !strings.Contains(config, `<arg name="extensions" value="php,inc"/>`) {
+		t.Errorf("phpcs.xml does not list -phpcs-ext:\n%s", config)
+	}
+}
+
+func TestCustomExtensions(t *testing.T) {
+	inTempDir(t)
+	m, _ := loadTestModel(t, "-eslint", "-docroot", ".", "-eslint-ext", "js, .mjs,*.cjs")
+	if got := commandsFor(t, m, "*.{js,mjs,cjs}"); !slices.Equal(got, []string{"eslint --fix"}) {
+		t.Errorf("*.{js,mjs,cjs} runs %v", got)
+	}
+	m.extensions["eslint"] = []string{"ts"}
+	if _, ok := lintStagedConfig(m).get("*.ts"); !ok {
+		t.Errorf("a single extension is not a plain glob: %v", lintStagedConfig(m))
+	}
+}
+
+func TestYamllintGlob(t *testing.T) {
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["yamllint"] = true
+	// CI and deployment YAML sits outside the docroot.
+	if got := commandsFor(t, m, "*.{yml,yaml}"); !slices.Equal(got, []string{"yamllint"}) {
+		t.Errorf("*.{yml,yaml} runs %v", got)
+	}
+	if !strings.HasPrefix(yamllintConfig, "extends: relaxed\n") {
+		t.Errorf(".yamllint = %q", yamllintConfig)
+	}
+	if got := m.pythonRequirements(); !slices.Equal(got, []string{"yamllint"}) {
+		t.Errorf("requirements = %v", got)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "yamllint") {
+		t.Errorf("yamllint is installed with npm: %v", npm)
+	}
+}
+
+func TestGlobsAreSorted(t *testing.T) {
+	m := testModel()
+	for _, t := range toolRegistry() {
+		m.enabled[t.name] = true
+	}
+	m.enabled["biome"] = false
+	var globs []string
+	for _, f := range lintStagedConfig(m) {
+		globs = append(globs, f.key)
+	}
+	if len(globs) < 10 || !slices.IsSorted(globs) {
+		t.Errorf("globs are not sorted: %v", globs)
+	}
+	if first := generateLintStagedConfig(m); first != generateLintStagedConfig(m) {
+		t.Errorf("the generated config changes between runs")
+	}
+}
+
+func TestPhpcbfRunsBeforePhpcs(t *testing.T) {
+	m := testModel()
+	m.enabled["phpcs"] = true
+	got := commandsFor(t, m, drupalGlob)
+	want := []string{`sh -c 'vendor/bin/phpcbf --standard=phpcs.xml "$@" || [ $? -le 2 ]' phpcbf`, "vendor/bin/phpcs --standard=phpcs.xml"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s runs %v, want %v", drupalGlob, got, want)
+	}
+
+	// phpcbf exits with 1 and 2 when it fixed files, which must not fail
+	// the commit; phpcs reports what is left.
+	inTempDir(t)
+	if err := os.MkdirAll("vendor/bin", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for code, wantErr := range map[string]bool{"0": false, "1": false, "2": false, "3": true} {
+		writeConfig(t, "vendor/bin/phpcbf", "#!/bin/sh\nexit "+code+"\n")
+		if err := os.Chmod("vendor/bin/phpcbf", 0755); err != nil {
+			t.Fatal(err)
+		}
+		err := exec.Command("sh", "-c", m.phpcbfCommand()+" web/index.php").Run()
+		if (err != nil) != wantErr {
+			t.Errorf("phpcbf exiting with %s: error %v", code, err)
+		}
+	}
+}
+
+func TestBiomeReplacesEslintAndPrettier(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["biome"] = true, true, true
+	m = m.withoutReplacedTools()
+	if m.enabled["eslint"] || m.enabled["prettier"] {
+		t.Fatalf("eslint=%v prettier=%v next to biome", m.enabled["eslint"], m.enabled["prettier"])
+	}
+	config := lintStagedConfig(m)
+	if len(config) != 1 {
+		t.Errorf("config = %v, want only the biome entry", config)
+	}
+	if got := commandsFor(t, m, "*.{js,ts,jsx,tsx,json}"); !slices.Equal(got, []string{"biome check --write --no-errors-on-unmatched"}) {
+		t.Errorf("biome runs %v", got)
+	}
+	npm, _ := m.packageLists(gitHooksBackend{})
+	if !slices.Equal(npm, []string{"lint-staged", "@biomejs/biome"}) {
+		t.Errorf("packages = %v", npm)
+	}
+
+	// The flags turn the replaced tools off too.
+	inTempDir(t)
+	m, _ = loadTestModel(t, "-eslint", "-biome", "-docroot", ".")
+	if m.enabled["eslint"] || !m.enabled["biome"] {
+		t.Errorf("-eslint -biome: eslint=%v biome=%v", m.enabled["eslint"], m.enabled["biome"])
+	}
+}
+
+func TestValidateLintStagedConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["phpcs"] = true, true, true
+	valid := map[string]model{"js": m}
+	asJSON := m
+	asJSON.configFormat = formatJSON
+	valid["json"] = asJSON
+	functions := m
+	functions.lintStagedFunctions = true
+	valid["functions"] = functions
+	for name, m := range valid {
+		if err := validateLintStagedConfig(generateLintStagedConfig(m)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	broken := []string{
+		"module.exports = {\n  '*.js': ['eslint --fix',\n};\n",
+		"module.exports = {\n  '*.js': 'eslint', '*.css'\n};\n",
+		"module.exports = ['eslint'];\n",
+		"module.exports = {\n  '*.js': [],\n};\n",
+		"module.exports = {\n  '*.js': '',\n};\n",
+		"module.exports = {\n  '*.js': [1],\n};\n",
+		"module.exports = {\n  '*.js': 'eslint',\n}; extra\n",
+		`{"*.js": ["eslint --fix"]`,
+		`{"*.js": true}`,
+	}
+	for _, content := range broken {
+		if err := validateLintStagedConfig(content); err == nil {
+			t.Errorf("no error for %q", content)
+		}
+	}
+}
+
+func TestSQLFormatterGlobAndConfig(t *testing.T) {
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["sql-formatter"] = true
+	got := commandsFor(t, m, "*.sql")
+	if len(got) != 1 || !strings.Contains(got[0], "sql-formatter --fix --config .sql-formatter.json") {
+		t.Errorf("*.sql runs %v", got)
+	}
+	var config map[string]any
+	if err := json.Unmarshal([]byte(sqlFormatterConfig), &config); err != nil {
+		t.Fatalf(".sql-formatter.json does not parse: %v", err)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "sql-formatter") {
+		t.Errorf("packages = %v", npm)
+	}
+
+	// sql-formatter takes one file, so the command runs it for each.
+	inTempDir(t)
+	if err := os.MkdirAll("node_modules/.bin", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, "node_modules/.bin/sql-formatter", "#!/bin/sh\necho \"$4\" >> formatted\n")
+	if err := os.Chmod("node_modules/.bin/sql-formatter", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if output, err := exec.Command("sh", "-c", got[0]+` "a b.sql" c.sql`).CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if formatted := readFile(t, "formatted"); formatted != "a b.sql\nc.sql\n" {
+		t.Errorf("formatted %q", formatted)
+	}
+}
+
+func TestImageminGlob(t *testing.T) {
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["imagemin"] = true
+	if got := commandsFor(t, m, "*.{png,jpg,jpeg,gif,svg}"); !slices.Equal(got, []string{"imagemin-lint-staged"}) {
+		t.Errorf("images run %v", got)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "imagemin-lint-staged") {
+		t.Errorf("packages = %v", npm)
+	}
+	m.extensions = map[string][]string{"imagemin": {"webp"}}
+	if got := commandsFor(t, m, "*.webp"); !slices.Equal(got, []string{"imagemin-lint-staged"}) {
+		t.Errorf("*.webp runs %v", got)
+	}
+}
+
+func TestLintStagedFunctions(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["stylelint"] = true, true, true
+	m.lintStagedFunctions = true
+	want := "module.exports = {\n" +
+		"  '*.js': (files) => [`eslint --fix " + filesArgument + "`, `prettier --write " + filesArgument + "`],\n" +
+		"  '*.{css,scss,sass}': (files) => [`stylelint --fix " + filesArgument + "`],\n" +
+		"};\n"
+	got := generateLintStagedConfig(m)
+	if got != want {
+		t.Errorf("generated config:\n%s\nwant:\n%s", got, want)
+	}
+	// JSON cannot hold functions, so it keeps the command lists.
+	m.configFormat = formatJSON
+	if config := generateLintStagedConfig(m); strings.Contains(config, "=>") {
+		t.Errorf("the JSON config has functions:\n%s", config)
+	}
+
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	script := strings.Replace(got, "module.exports = ", "const config = ", 1) +
+		`console.log(JSON.stringify(config['*.js'](['a.js', 'my file.js'])));`
+	output, err := exec.Command("node", "-e", script).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	var commands []string
+	if err := json.Unmarshal(output, &commands); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	if !slices.Equal(commands, []string{`eslint --fix "a.js" "my file.js"`, `prettier --write "a.js" "my file.js"`}) {
+		t.Errorf("the *.js function returns %q", commands)
+	}
+}
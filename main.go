@@ -1,47 +1,216 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type model struct {
-	index              int
-	docroot            string
-	eslint             bool
-	prettier           bool
-	stylelint          bool
-	secretlint         bool
-	phpcs              bool
-	validateBranchName bool
-	jiraPrepareCommit  bool
+	index               int
+	docrootInput        string
+	docroots            []string
+	docrootCandidates   []string
+	docrootDetected     bool
+	enabled             map[string]bool
+	protectedBranches   []string
+	branchesInput       string
+	phpstanLevel        int
+	prePush             map[string]bool
+	pin                 bool
+	lintStagedFunctions bool
+	concurrency         int
+	nodeVersion         string
+	detectedNode        string
+	profile             bool
+	skipCI              bool
+	timings             *phaseTimings
+	phpcsStandard       string
+	listTools           bool
+	configDir           string
+	versions            map[string]string
+	licenseTemplate     string
+	jiraKey             string
+	extensions          map[string][]string
+	react               bool
+	preset              string
+	choosingPreset      bool
+	presetBase          map[string]bool
+	presetCursor        int
+	toolCursor          int
+	yes                 bool
+	interactive         bool
+	dryRun              bool
+	verify              bool
+	force               bool
+	uninstall           bool
+	verbose             bool
+	quiet               bool
+	packageManager      packageManager
+	configFormat        configFormat
+	huskyMajor          int
+	backend             string
+	answerBuffer        string
+	cancelled           bool
+	confirmed           bool
+	inputError          string
+	installing          bool
+	done                bool
+	setupErr            error
+	created             *artifacts
+	added               *artifacts
+	notices             *strings.Builder
+	setupNotices        string
+	verboseLog          *strings.Builder
+	setupLog            string
+	spinner             spinner.Model
+	width               int
+	height              int
+}
+
+type setupDoneMsg struct {
+	added   *artifacts
+	err     error
+	notices string
+	log     string
 }
 
 var questions = []string{
-	"Give the path of your docroot (auto-detect if current directory has 'docroot' or 'web' folder): ",
-	"Do you want to add eslint for JS? (y/n): ",
-	"Do you want to add prettier support? (y/n): ",
-	"Do you want to add stylelint for CSS and SCSS? (y/n): ",
-	"Do you want to add secretlint for all files? (y/n): ",
-	"Do you want to add PHPCS and PHPCBF for PHP and all Drupal PHP files? (y/n): ",
-	"Do you want to add support for validating branch name pattern using validate-branch-name npm package? (y/n): ",
-	"Do you want to add support to automatically add ticket number in commit message using jira-prepare-commit-msg npm package? (y/n): ",
+	"Give the path of your docroot, or several comma-separated paths in a monorepo (auto-detect if current directory has 'docroot' or 'web' folder): ",
+	"Choose the tools to set up:",
+	"Which coding standard should PHPCS check: drupal, psr12 or wordpress? ",
+	"Do you want prettier to format PHP files too, before PHPCS checks them?",
+	"Give your JIRA project key, e.g. ABC, or a regex matching your ticket numbers (empty matches any key): ",
+	"Which branches should commits be rejected on (comma-separated)? ",
+	"Which Node version should everyone use, for .nvmrc and engines.node in package.json (empty to skip)? ",
 }
 
+// Indexes into questions of the tool checklist and of the follow-up questions
+// asked for some of the tools.
+const (
+	toolsQuestion         = 1
+	phpcsStandardQuestion = 2
+	prettierPHPQuestion   = 3
+	jiraKeyQuestion       = 4
+	branchesQuestion      = 5
+	nodeVersionQuestion   = 6
+)
+
 func main() {
-	p := tea.NewProgram(initialModel())
-	if err := p.Start(); err != nil {
-		fmt.Printf("Error running program: %v\n", err)
+	// Hooks, package.json and the config files belong at the top of the
+	// repository, also when the tool is started from a subdirectory.
+	if root, err := gitRoot(); err == nil {
+		if err := os.Chdir(root); err != nil {
+			fmt.Fprintf(os.Stderr, "Error changing to the git root: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	m, nonInteractive := loadModel(os.Args[1:])
+	if m.packageManager == "" {
+		m.packageManager = detectPackageManager()
+	}
+	if missing := missingBinaries(pmBinaries(m.packageManager)...); len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Missing required tools: %s\n", strings.Join(missing, ", "))
+		fmt.Fprintln(os.Stderr, "Install Node.js (https://nodejs.org) and your package manager, and make sure they are on your PATH.")
+		os.Exit(1)
+	}
+	if m.uninstall {
+		if err := uninstall(m); err != nil {
+			fmt.Fprintf(os.Stderr, "Error uninstalling: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	ensureGitRepo(m)
+	if nonInteractive {
+		m.infof("Setting up Git pre-commit hooks...\n")
+		added, err := setupHooks(m)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up Git hooks: %v\n", err)
+			os.Exit(1)
+		}
+		m.added = added
+		m.infof("%s", m.doneMessage())
+		return
+	}
+	p := tea.NewProgram(m)
+	final, err := p.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprint(logOutput, final.(model).setupLog)
+	if final.(model).setupErr != nil {
 		os.Exit(1)
 	}
 }
 
+var lookPath = exec.LookPath
+
+func missingBinaries(names ...string) []string {
+	var missing []string
+	for _, name := range names {
+		if _, err := lookPath(name); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func gitRoot() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("finding the git root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func ensureGitRepo(m model) {
+	if _, err := gitRoot(); err == nil {
+		return
+	}
+	m.infof("This directory is not a git repository.\n")
+	if !m.yes && !confirm("Run `git init` now? (y/n): ") {
+		fmt.Fprintln(os.Stderr, "Git hooks can only be set up inside a git repository.")
+		os.Exit(1)
+	}
+	if err := m.runCommand("git", "init"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing git repository: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// stdin is shared by all prompts, as a reader of its own would buffer away
+// the answers to the prompts after it.
+var stdin = bufio.NewReader(os.Stdin)
+
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 func initialModel() model {
-	return model{}
+	return model{
+		choosingPreset:    true,
+		enabled:           map[string]bool{},
+		phpstanLevel:      5,
+		phpcsStandard:     "drupal",
+		protectedBranches: defaultProtectedBranches,
+		docrootCandidates: defaultDocrootCandidates,
+		licenseTemplate:   "license-header.txt",
+		spinner:           spinner.New(spinner.WithSpinner(spinner.Dot)),
+	}
 }
 
 func (m model) Init() tea.Cmd {
@@ -50,131 +219,468 @@ func (m model) Init() tea.Cmd {
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case setupDoneMsg:
+		m.installing = false
+		m.done = true
+		m.setupErr = msg.err
+		m.added = msg.added
+		m.setupNotices = msg.notices
+		m.setupLog = msg.log
+		return m, tea.Quit
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+	case spinner.TickMsg:
+		if !m.installing {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
 	case tea.KeyMsg:
+		if m.installing {
+			// Quitting now would leave the setup half done while claiming
+			// nothing was written, so keys wait until it finishes.
+			return m, nil
+		}
+		if m.choosingPreset && msg.String() != "ctrl+c" && msg.String() != "q" {
+			return m.updatePresetChoice(msg), nil
+		}
+		if m.index == toolsQuestion && msg.String() != "ctrl+c" && msg.String() != "q" {
+			return m.updateToolChoice(msg), nil
+		}
 		switch msg.String() {
+		case "ctrl+c":
+			m.cancelled = true
+			return m, tea.Quit
+		case "q":
+			if *m.activeInput() == "" {
+				m.cancelled = true
+				return m, tea.Quit
+			}
+			*m.activeInput() += msg.String()
 		case "enter":
+			if m.index == len(questions) {
+				m = m.withoutReplacedTools()
+				m.confirmed = true
+				m.installing = true
+				return m, tea.Batch(m.spinner.Tick, runSetup(m))
+			}
 			if m.index == 0 {
-				if m.docroot == "" {
-					if _, err := os.Stat("docroot"); err == nil {
-						m.docroot = "docroot"
-					} else if _, err := os.Stat("web"); err == nil {
-						m.docroot = "web"
-					} else {
-						m.docroot = "."
+				docroots := parseDocroots(m.docrootInput)
+				if len(docroots) == 0 {
+					docroots = []string{detectDocroot(m.docrootCandidates)}
+				} else if missing := missingPath(docroots); missing != "" {
+					m.inputError = fmt.Sprintf("Path %s not found, try again", missing)
+					return m, nil
+				}
+				m.docroots = docroots
+				m.docrootDetected = len(parseDocroots(m.docrootInput)) == 0
+			} else if m.index == phpcsStandardQuestion {
+				standard, err := parsePhpcsStandard(m.phpcsStandard)
+				if err != nil {
+					m.inputError = "Please answer " + strings.Join(phpcsStandardNames, ", ")
+					return m, nil
+				}
+				m.phpcsStandard = standard
+			} else if m.index == jiraKeyQuestion {
+				m.jiraKey = strings.TrimSpace(m.jiraKey)
+			} else if m.index == branchesQuestion {
+				branches := parseBranches(m.branchesInput)
+				if len(branches) == 0 {
+					m.inputError = "Please give at least one branch"
+					return m, nil
+				}
+				m.protectedBranches = branches
+			} else if m.index == nodeVersionQuestion {
+				if strings.TrimSpace(m.nodeVersion) != "" {
+					version, err := parseNodeVersion(m.nodeVersion)
+					if err != nil {
+						m.inputError = "Please give a version like 20 or 20.11.1, or leave it empty"
+						return m, nil
 					}
+					m.nodeVersion = version
+				} else {
+					m.nodeVersion = ""
 				}
 			} else {
-				answer := strings.ToLower(strings.TrimSpace(msg.String()))
-				switch m.index {
-				case 1:
-					m.eslint = (answer == "y")
-				case 2:
-					m.prettier = (answer == "y")
-				case 3:
-					m.stylelint = (answer == "y")
-				case 4:
-					m.secretlint = (answer == "y")
-				case 5:
-					m.phpcs = (answer == "y")
-				case 6:
-					m.validateBranchName = (answer == "y")
-				case 7:
-					m.jiraPrepareCommit = (answer == "y")
+				if answer := m.answerBuffer; strings.TrimSpace(answer) != "" {
+					yes, ok := parseYesNo(answer)
+					if !ok {
+						m.inputError = "Please answer y or n"
+						return m, nil
+					}
+					m.enabled[answerTool(m.index)] = yes
 				}
+				m.answerBuffer = ""
 			}
-			m.index++
-			if m.index >= len(questions) {
-				setupGitHooks(m)
+			m = m.nextQuestion()
+		case "esc":
+			if m.index == len(questions) {
+				m.cancelled = true
 				return m, tea.Quit
 			}
+			m = m.previousQuestion()
+		case "left", "right":
+			if m.isYesNoQuestion(m.index) {
+				m.enabled[answerTool(m.index)] = msg.String() == "left"
+				m.answerBuffer = ""
+				m.inputError = ""
+			} else if msg.String() == "left" {
+				m = m.previousQuestion()
+			}
+		case "backspace", "delete":
+			input := m.activeInput()
+			*input = trimLastRune(*input)
+		case "ctrl+u":
+			// Like readline, Ctrl+U clears the whole line.
+			*m.activeInput() = ""
 		default:
-			if m.index == 0 {
-				m.docroot += msg.String()
+			if m.index == len(questions) {
+				break
+			}
+			if msg.Type == tea.KeySpace {
+				*m.activeInput() += " "
+			} else if msg.Type == tea.KeyRunes {
+				// Pasted text arrives as one message, which msg.String()
+				// would wrap in brackets, and may end in a newline.
+				*m.activeInput() += strings.Map(dropControl, string(msg.Runes))
 			}
 		}
 	}
 	return m, nil
 }
 
-func (m model) View() string {
-	if m.index >= len(questions) {
-		return "Setting up your Git pre-commit hooks...\n"
+func dropControl(r rune) rune {
+	if unicode.IsControl(r) {
+		return -1
+	}
+	return r
+}
+
+// defaultDocrootCandidates are the folders Drupal projects commonly use as
+// their docroot, in the order they are looked for.
+var defaultDocrootCandidates = []string{"docroot", "web"}
+
+// detectDocroot returns the first candidate folder that exists, or "." when
+// none does.
+func detectDocroot(candidates []string) string {
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+	}
+	return "."
+}
+
+func parseDocroots(input string) []string {
+	var docroots []string
+	for _, docroot := range strings.Split(input, ",") {
+		if docroot = strings.TrimSpace(docroot); docroot != "" {
+			docroots = append(docroots, cleanDocroot(docroot))
+		}
+	}
+	return docroots
+}
+
+// cleanDocroot normalizes a docroot such as ./web/ to web, so that the globs
+// built from it do not end up with ./ or // in them.
+func cleanDocroot(docroot string) string {
+	return filepath.ToSlash(filepath.Clean(docroot))
+}
+
+func missingPath(paths []string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return path
+		}
 	}
-	return questions[m.index]
+	return ""
 }
 
-func setupGitHooks(m model) {
-	fmt.Println("Setting up Git pre-commit hooks...")
-	installPackages := []string{"husky", "lint-staged"}
-	if m.eslint {
-		installPackages = append(installPackages, "eslint")
-		writeFile(".eslintrc.js", "module.exports = {\n  // ESLint configuration\n};\n")
+func (m model) nextQuestion() model {
+	m.inputError = ""
+	m.index++
+	for m.index < len(questions) && !m.questionApplies(m.index) {
+		m.index++
 	}
-	if m.prettier {
-		installPackages = append(installPackages, "prettier")
-		writeFile(".prettierrc.js", "module.exports = {\n  // Prettier configuration\n};\n")
+	return m
+}
+
+func (m model) previousQuestion() model {
+	m.inputError = ""
+	if m.index == 0 {
+		m.choosingPreset = true
+		return m
 	}
-	if m.stylelint {
-		installPackages = append(installPackages, "stylelint")
-		writeFile(".stylelintrc.js", "module.exports = {\n  // Stylelint configuration\n};\n")
+	m.index--
+	for m.index > 0 && !m.questionApplies(m.index) {
+		m.index--
 	}
-	if m.secretlint {
-		installPackages = append(installPackages, "secretlint")
-		writeFile(".secretlintrc.js", "module.exports = {\n  // Secretlint configuration\n};\n")
+	m.answerBuffer = ""
+	return m
+}
+
+// questionApplies reports whether the question at index is asked, given the
+// earlier answers. Follow-up questions are skipped when their tool is off.
+func (m model) questionApplies(index int) bool {
+	switch index {
+	case prettierPHPQuestion:
+		return m.enabled["prettier"] && m.enabled["phpcs"] && m.phpcsRules().prettier != nil
+	case phpcsStandardQuestion:
+		return m.enabled["phpcs"]
+	case jiraKeyQuestion:
+		return m.enabled["jira-prepare-commit-msg"]
+	case branchesQuestion:
+		return m.enabled["protect-branches"]
+	case nodeVersionQuestion:
+		// Only projects whose hooks run on Node need it pinned.
+		npm, _ := m.packageLists(m.hookBackend())
+		return len(npm) > 0
 	}
-	if m.phpcs {
-		installPackages = append(installPackages, "phpcs")
-		writeFile("phpcs.xml", "<ruleset name=\"Drupal\">\n  <!-- PHPCS configuration for Drupal -->\n</ruleset>\n")
+	return true
+}
+
+func (m *model) isYesNoQuestion(index int) bool {
+	return index < len(questions) && answerTool(index) != ""
+}
+
+func (m *model) activeInput() *string {
+	switch m.index {
+	case 0:
+		return &m.docrootInput
+	case phpcsStandardQuestion:
+		return &m.phpcsStandard
+	case jiraKeyQuestion:
+		return &m.jiraKey
+	case branchesQuestion:
+		return &m.branchesInput
+	case nodeVersionQuestion:
+		return &m.nodeVersion
 	}
-	if m.validateBranchName {
-		installPackages = append(installPackages, "validate-branch-name")
-		writeFile(".validate-branch-namerc.js", "module.exports = {\n  // validate-branch-name configuration\n};\n")
+	return &m.answerBuffer
+}
+
+// answerTool is the follow-up tool a yes/no question turns on or off.
+func answerTool(index int) string {
+	switch index {
+	case prettierPHPQuestion:
+		return "prettier-php"
 	}
-	if m.jiraPrepareCommit {
-		installPackages = append(installPackages, "jira-prepare-commit-msg")
-		writeFile(".prepare-commit-msg", "#!/bin/sh\n# Script to automatically add ticket number to commit message\n")
+	return ""
+}
+
+func trimLastRune(s string) string {
+	if s == "" {
+		return s
 	}
-	runCommand("npm", append([]string{"install", "--save-dev"}, installPackages...)...)
-	runCommand("npx", "husky", "install")
-	runCommand("npx", "husky", "add", ".husky/pre-commit", "npx lint-staged")
-	writeFile(".lintstagedrc.js", generateLintStagedConfig(m))
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
 }
 
-func generateLintStagedConfig(m model) string {
-	config := "module.exports = {\n"
-	if m.eslint {
-		config += "  '*.js': ['eslint --fix'],\n"
+func (m model) View() string {
+	if m.cancelled {
+		return "Setup cancelled, no files written.\n"
+	}
+	if m.done {
+		var notices string
+		if m.setupNotices != "" {
+			notices = m.setupNotices + "\n"
+		}
+		if m.setupErr != nil {
+			return notices + fmt.Sprintf("✗ Setup failed: %v\n", m.setupErr)
+		}
+		return notices + m.doneMessage()
 	}
-	if m.prettier {
-		config += "  '*.js': ['prettier --write'],\n"
+	if m.installing {
+		return m.spinner.View() + " Installing packages and setting up your Git pre-commit hooks...\n"
 	}
-	if m.stylelint {
-		config += "  '*.css': ['stylelint --fix'],\n"
+	if m.choosingPreset {
+		return m.presetView()
 	}
-	if m.secretlint {
-		config += "  '*.*': ['secretlint'],\n"
+	if m.index == len(questions) {
+		return m.summary()
 	}
-	if m.phpcs {
-		config += "  '*.php': ['phpcs --standard=phpcs.xml'],\n"
+	question := wrapText(fmt.Sprintf("[%d/%d] ", m.index+1, len(questions))+questions[m.index], m.width)
+	if m.index == toolsQuestion {
+		return m.toolsView(question)
 	}
-	config += "};\n"
-	return config
+	if !m.isYesNoQuestion(m.index) {
+		view := question + *m.activeInput()
+		if m.index == nodeVersionQuestion && m.nodeVersion == "" && m.detectedNode != "" {
+			// A hint only: the version is pinned when the user types it.
+			view += fmt.Sprintf("\n(the installed Node is version %s)\n", m.detectedNode)
+		}
+		if m.inputError != "" {
+			view += "\n" + m.inputError + "\n"
+		}
+		return view
+	}
+	// prettier-php is the only yes/no question left, and it defaults to no.
+	view := question + " (y/N)" + "\n\n" + yesNoSelector(m.enabled[answerTool(m.index)]) + m.answerBuffer + "\n"
+	if m.inputError != "" {
+		view += m.inputError + "\n"
+	}
+	return view + "\n(←/→ to choose, Enter to confirm, Esc to go back)\n"
 }
 
-func writeFile(filename, content string) {
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
-		fmt.Printf("Error writing file: %v\n", err)
-		os.Exit(1)
+// wrapText breaks text between words so that no line is wider than width. A
+// width of 0, before the terminal size is known, leaves text as is. The
+// trailing space of the docroot question is kept for the typed input.
+func wrapText(text string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	var b strings.Builder
+	lineLength := 0
+	for _, word := range strings.Fields(text) {
+		wordLength := utf8.RuneCountInString(word)
+		if lineLength > 0 && lineLength+1+wordLength > width {
+			b.WriteByte('\n')
+			lineLength = 0
+		} else if lineLength > 0 {
+			b.WriteByte(' ')
+			lineLength++
+		}
+		b.WriteString(word)
+		lineLength += wordLength
 	}
+	if strings.HasSuffix(text, " ") {
+		b.WriteByte(' ')
+	}
+	return b.String()
 }
 
-func runCommand(cmdName string, args ...string) {
-	cmd := exec.Command(cmdName, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Error running command: %v\n", err)
-		os.Exit(1)
+func parseYesNo(answer string) (yes, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, true
+	case "n", "no":
+		return false, true
+	}
+	return false, false
+}
+
+func (m model) doneMessage() string {
+	var b strings.Builder
+	if m.dryRun {
+		// Nothing was written or run, so there is nothing to list.
+		b.WriteString("Dry run finished: no files were written and no packages installed.\n")
+		b.WriteString("Run pre-committer again without -dry-run to set up the hooks.\n")
+		if m.timings != nil {
+			b.WriteString("\nSetup phases:\n" + m.timings.String())
+		}
+		return b.String()
+	}
+	b.WriteString("✓ Git pre-commit hooks are set up.\n")
+	if m.verify {
+		b.WriteString("✓ The pre-commit hook ran successfully.\n")
+	}
+	if m.added != nil {
+		if len(m.added.Files) > 0 {
+			b.WriteString("\nCreated files:\n")
+			for _, name := range m.added.Files {
+				fmt.Fprintf(&b, "  + %s\n", name)
+			}
+		}
+		if len(m.added.Hooks) > 0 {
+			b.WriteString("\nAdded hooks:\n")
+			for _, hook := range m.added.Hooks {
+				fmt.Fprintf(&b, "  + %s: %s\n", hook.Path, hook.Command)
+			}
+		}
+	}
+	if m.timings != nil {
+		b.WriteString("\nSetup phases:\n" + m.timings.String())
+	}
+	b.WriteString("\nNext steps:\n")
+	if len(m.pythonRequirements()) > 0 {
+		b.WriteString("  - Install the Python tools: pip install -r requirements-dev.txt\n")
+	}
+	for _, t := range m.enabledTools() {
+		if t.nextStep != "" {
+			fmt.Fprintf(&b, "  - %s\n", t.nextStep)
+		}
+	}
+	if scripts := packageScripts(m); len(scripts) > 0 {
+		pm := m.packageManager
+		if pm == "" {
+			pm = detectPackageManager()
+		}
+		for _, script := range scripts {
+			fmt.Fprintf(&b, "  - Run %s on the whole project: %s\n", script.key, strings.Join(pmRun(pm, script.key), " "))
+		}
+	}
+	b.WriteString("  - Commit a change to see the hooks run: git commit\n")
+	return b.String()
+}
+
+func yesNoSelector(yes bool) string {
+	if yes {
+		return "  (•) Yes   ( ) No   "
+	}
+	return "  ( ) Yes   (•) No   "
+}
+
+func (m model) summary() string {
+	var b strings.Builder
+	b.WriteString("Summary of your choices:\n\n")
+	fmt.Fprintf(&b, "  Docroot: %s", strings.Join(m.docroots, ", "))
+	if m.docrootDetected {
+		b.WriteString(" (auto-detected)")
+	}
+	b.WriteString("\n")
+	if m.preset != "" && m.preset != "none" {
+		fmt.Fprintf(&b, "  Preset: %s\n", m.preset)
+	}
+	for _, t := range m.enabledTools() {
+		if m.prePush[t.name] {
+			fmt.Fprintf(&b, "  + %s (pre-push)\n", t.name)
+		} else {
+			fmt.Fprintf(&b, "  + %s\n", t.name)
+		}
+	}
+	for _, t := range m.enabledTools() {
+		if slices.ContainsFunc(t.replaces, func(name string) bool { return m.enabled[name] }) {
+			fmt.Fprintf(&b, "  (%s replaces %s, which are skipped)\n", t.name, strings.Join(t.replaces, " and "))
+		}
+	}
+	if m.enabled["phpcs"] {
+		fmt.Fprintf(&b, "  PHPCS standard: %s\n", m.phpcsRules().title)
+	}
+	if m.enabled["jira-prepare-commit-msg"] && m.jiraKey != "" {
+		fmt.Fprintf(&b, "  JIRA key: %s\n", m.jiraKey)
+	}
+	if m.enabled["protect-branches"] {
+		fmt.Fprintf(&b, "  Protected branches: %s\n", strings.Join(m.protectedBranches, ", "))
+	}
+	if m.nodeVersion != "" {
+		fmt.Fprintf(&b, "  Node version: %s\n", m.nodeVersion)
+	}
+	if npm, composer := m.withoutReplacedTools().packagesToInstall(); len(npm) > 0 || len(composer) > 0 {
+		b.WriteString("\n" + packageList(npm, composer))
+	}
+	b.WriteString("\nPress Enter to set up the hooks or Esc to cancel.\n")
+	return b.String()
+}
+
+// setupHooks runs the setup once the wizard is confirmed. It is a variable so
+// that the wizard can be stepped through without touching the project.
+var setupHooks = setupGitHooks
+
+func runSetup(m model) tea.Cmd {
+	return func() tea.Msg {
+		// The wizard owns the terminal while the setup runs, so its notices
+		// are shown when it is done rather than printed over the spinner,
+		// and the -verbose log is printed once the wizard has exited.
+		m.notices = &strings.Builder{}
+		m.verboseLog = &strings.Builder{}
+		added, err := setupHooks(m)
+		if err == nil && !m.dryRun {
+			// Saving the answers lets the setup be repeated or shared
+			// without going through the wizard again.
+			err = saveConfig(m, configFileName)
+		}
+		return setupDoneMsg{added, err, m.notices.String(), m.verboseLog.String()}
 	}
 }
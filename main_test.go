@@ -0,0 +1,798 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inTempDir runs the test in an empty directory, so that the wizard's checks
+// for paths and the config it saves do not touch the repository.
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// stubSetupHooks replaces the setup with one that records the models it was
+// called with.
+func stubSetupHooks(t *testing.T) *[]model {
+	t.Helper()
+	var calls []model
+	original := setupHooks
+	setupHooks = func(m model) (*artifacts, error) {
+		calls = append(calls, m)
+		return &artifacts{Files: []string{".husky/pre-commit"}}, nil
+	}
+	t.Cleanup(func() { setupHooks = original })
+	return &calls
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "space":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// press feeds keys to the model one by one and returns the model and the
+// command of the last key.
+func press(t *testing.T, m model, keys ...string) (model, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var next tea.Model
+		next, cmd = m.Update(key(k))
+		m = next.(model)
+	}
+	return m, cmd
+}
+
+// typeText types text as a single message, the way a paste arrives.
+func typeText(m model, text string) model {
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return next.(model)
+}
+
+// runCmd runs cmd and the commands of any batch it returns, and passes the
+// messages they produce to the model, as the Bubble Tea runtime would.
+func runCmd(m model, cmd tea.Cmd) model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = runCmd(m, c)
+		}
+		return m
+	}
+	if _, ok := msg.(tea.QuitMsg); ok {
+		return m
+	}
+	next, _ := m.Update(msg)
+	return next.(model)
+}
+
+// moveToTool moves the checklist cursor to the named tool.
+func moveToTool(t *testing.T, m model, name string) model {
+	t.Helper()
+	for m.toolCursor > 0 {
+		m, _ = press(t, m, "up")
+	}
+	for _, option := range checklistOptions() {
+		if option.name == name {
+			return m
+		}
+		m, _ = press(t, m, "down")
+	}
+	t.Fatalf("tool %q is not in the checklist", name)
+	return m
+}
+
+func TestWizardWalksAllQuestions(t *testing.T) {
+	inTempDir(t)
+	if err := os.Mkdir("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	calls := stubSetupHooks(t)
+
+	m := initialModel()
+	if !strings.Contains(m.View(), "Choose a preset") {
+		t.Fatalf("first frame is not the preset choice:\n%s", m.View())
+	}
+	m, _ = press(t, m, "enter")
+	if m.choosingPreset || m.index != 0 {
+		t.Fatalf("after choosing the preset: choosingPreset=%v index=%d", m.choosingPreset, m.index)
+	}
+	if !strings.HasPrefix(m.View(), "[1/7] Give the path of your docroot") {
+		t.Fatalf("unexpected docroot frame:\n%s", m.View())
+	}
+
+	m = typeText(m, "web")
+	m, _ = press(t, m, "enter")
+	if m.index != toolsQuestion {
+		t.Fatalf("after the docroot: index=%d, want %d", m.index, toolsQuestion)
+	}
+	if !strings.Contains(m.View(), "> [x] eslint") {
+		t.Fatalf("checklist frame does not start on eslint:\n%s", m.View())
+	}
+	for _, name := range []string{"phpcs", "protect-branches", "jira-prepare-commit-msg"} {
+		m = moveToTool(t, m, name)
+		m, _ = press(t, m, "space")
+	}
+	m, _ = press(t, m, "enter")
+
+	if m.index != phpcsStandardQuestion {
+		t.Fatalf("after the checklist: index=%d, want %d", m.index, phpcsStandardQuestion)
+	}
+	m, _ = press(t, m, "ctrl+u")
+	m = typeText(m, "psr12")
+	m, _ = press(t, m, "enter")
+	if m.index != prettierPHPQuestion {
+		t.Fatalf("after the PHPCS standard: index=%d, want %d", m.index, prettierPHPQuestion)
+	}
+	if view := m.View(); !strings.Contains(view, "(y/N)") || !strings.Contains(view, "( ) Yes   (•) No") {
+		t.Fatalf("prettier-php does not default to no:\n%s", view)
+	}
+	m, _ = press(t, m, "left")
+	if !strings.Contains(m.View(), "(•) Yes   ( ) No") {
+		t.Fatalf("← does not select yes:\n%s", m.View())
+	}
+	m, _ = press(t, m, "enter")
+	if m.index != jiraKeyQuestion {
+		t.Fatalf("after the PHPCS standard: index=%d, want %d", m.index, jiraKeyQuestion)
+	}
+	m = typeText(m, " ABC ")
+	m, _ = press(t, m, "enter")
+	if m.index != branchesQuestion {
+		t.Fatalf("after the JIRA key: index=%d, want %d", m.index, branchesQuestion)
+	}
+	m = typeText(m, "main, release")
+	m, _ = press(t, m, "enter")
+	if m.index != nodeVersionQuestion {
+		t.Fatalf("after the branches: index=%d, want %d", m.index, nodeVersionQuestion)
+	}
+	m = typeText(m, "v20")
+	m, _ = press(t, m, "enter")
+
+	if m.index != len(questions) {
+		t.Fatalf("after the Node version: index=%d, want the summary", m.index)
+	}
+	summary := m.View()
+	for _, want := range []string{"Docroot: web\n", "  + phpcs\n", "PHPCS standard: PSR-12", "JIRA key: ABC", "Protected branches: main, release", "Node version: 20"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, summary)
+		}
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("setup ran before the summary was confirmed")
+	}
+
+	m, cmd := press(t, m, "enter")
+	if !m.installing || !strings.Contains(m.View(), "Installing packages") {
+		t.Fatalf("confirming the summary does not show the spinner:\n%s", m.View())
+	}
+	m = runCmd(m, cmd)
+
+	if len(*calls) != 1 {
+		t.Fatalf("setup ran %d times, want once", len(*calls))
+	}
+	got := (*calls)[0]
+	if !slices.Equal(got.docroots, []string{"web"}) {
+		t.Errorf("docroots = %v, want [web]", got.docroots)
+	}
+	if !got.enabled["eslint"] || !got.enabled["prettier"] || !got.enabled["stylelint"] || !got.enabled["secretlint"] || !got.enabled["editorconfig"] || !got.enabled["phpcs"] || !got.enabled["prettier-php"] || !got.enabled["protect-branches"] || !got.enabled["jira-prepare-commit-msg"] {
+		t.Errorf("selected tools are not set: %+v", got)
+	}
+	if got.phpcsStandard != "psr12" || got.jiraKey != "ABC" || got.nodeVersion != "20" {
+		t.Errorf("phpcsStandard=%q jiraKey=%q nodeVersion=%q", got.phpcsStandard, got.jiraKey, got.nodeVersion)
+	}
+	if !slices.Equal(got.protectedBranches, []string{"main", "release"}) {
+		t.Errorf("protectedBranches = %v", got.protectedBranches)
+	}
+	if !m.done || m.setupErr != nil {
+		t.Fatalf("done=%v setupErr=%v", m.done, m.setupErr)
+	}
+	if view := m.View(); !strings.Contains(view, "✓ Git pre-commit hooks are set up.") || !strings.Contains(view, "+ .husky/pre-commit") {
+		t.Errorf("unexpected final frame:\n%s", view)
+	}
+	if _, err := os.Stat(configFileName); err != nil {
+		t.Errorf("the answers were not saved: %v", err)
+	}
+}
+
+func TestWizardCancelDoesNotRunSetup(t *testing.T) {
+	inTempDir(t)
+	calls := stubSetupHooks(t)
+	// With the defaults, the checklist is followed only by the Node version.
+	m, _ := press(t, initialModel(), "enter", "enter", "enter", "enter")
+	if m.index != len(questions) {
+		t.Fatalf("expected the summary, got question %d", m.index)
+	}
+	m, _ = press(t, m, "esc")
+	if !m.cancelled {
+		t.Fatalf("Esc on the summary does not cancel")
+	}
+	if m.View() != "Setup cancelled, no files written.\n" {
+		t.Errorf("unexpected frame: %q", m.View())
+	}
+	if len(*calls) != 0 {
+		t.Errorf("setup ran after cancelling")
+	}
+}
+
+// atQuestion returns a wizard past the preset choice, at question index.
+func atQuestion(index int) model {
+	m := initialModel()
+	m.choosingPreset = false
+	m.docroots = []string{"."}
+	m.index = index
+	return m
+}
+
+func TestYesNoAnswerIsBufferedUntilEnter(t *testing.T) {
+	m := atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	m, _ = press(t, m, "y")
+	if m.answerBuffer != "y" || m.enabled["prettier-php"] {
+		t.Fatalf("after y: answerBuffer=%q prettierPHP=%v", m.answerBuffer, m.enabled["prettier-php"])
+	}
+	m, _ = press(t, m, "enter")
+	if !m.enabled["prettier-php"] || m.answerBuffer != "" || m.index != nodeVersionQuestion {
+		t.Errorf("after Enter: prettierPHP=%v answerBuffer=%q index=%d", m.enabled["prettier-php"], m.answerBuffer, m.index)
+	}
+
+	m = atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"], m.enabled["prettier-php"] = true, true, true
+	m, _ = press(t, m, "n", "o", "enter")
+	if m.enabled["prettier-php"] {
+		t.Errorf("answering no leaves prettierPHP set")
+	}
+}
+
+func TestBackspaceTrimsTheInput(t *testing.T) {
+	m := atQuestion(0)
+	m, _ = press(t, m, "w", "e", "e", "b", "backspace", "backspace", "b")
+	if m.docrootInput != "web" {
+		t.Errorf("docrootInput = %q, want web", m.docrootInput)
+	}
+
+	m = atQuestion(prettierPHPQuestion)
+	m, _ = press(t, m, "y", "e", "x", "backspace", "s")
+	if m.answerBuffer != "yes" {
+		t.Errorf("answerBuffer = %q, want yes", m.answerBuffer)
+	}
+}
+
+func TestQuitLeavesTheProjectUntouched(t *testing.T) {
+	dir := inTempDir(t)
+	calls := stubSetupHooks(t)
+	for _, k := range []string{"ctrl+c", "q"} {
+		m := atQuestion(prettierPHPQuestion)
+		m.enabled["prettier"], m.enabled["phpcs"] = true, true
+		m, cmd := press(t, m, k)
+		if !m.cancelled || cmd == nil {
+			t.Fatalf("%s: cancelled=%v, quit command=%v", k, m.cancelled, cmd != nil)
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("%s does not quit", k)
+		}
+		if m.View() != "Setup cancelled, no files written.\n" {
+			t.Errorf("%s: unexpected frame %q", k, m.View())
+		}
+	}
+	if len(*calls) != 0 {
+		t.Errorf("setup ran after quitting")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("quitting wrote %d files", len(entries))
+	}
+}
+
+func TestQIsTypedWhileEditingInput(t *testing.T) {
+	m := atQuestion(jiraKeyQuestion)
+	m.enabled["jira-prepare-commit-msg"] = true
+	m, _ = press(t, m, "A", "q")
+	if m.cancelled || m.jiraKey != "Aq" {
+		t.Errorf("cancelled=%v jiraKey=%q", m.cancelled, m.jiraKey)
+	}
+}
+
+func TestGoingBackKeepsTheAnswerEditable(t *testing.T) {
+	inTempDir(t)
+	for _, dir := range []string{"web", "docroot"} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := atQuestion(0)
+	m = typeText(m, "web")
+	m, _ = press(t, m, "enter", "esc")
+	if m.index != 0 || m.docrootInput != "web" {
+		t.Fatalf("after going back: index=%d docrootInput=%q", m.index, m.docrootInput)
+	}
+	m, _ = press(t, m, "backspace", "backspace", "backspace")
+	m = typeText(m, "docroot")
+	m, _ = press(t, m, "enter")
+	if m.index != toolsQuestion || !slices.Equal(m.docroots, []string{"docroot"}) {
+		t.Errorf("index=%d docroots=%v, want the corrected docroot", m.index, m.docroots)
+	}
+
+	// Going back from the first question returns to the preset choice.
+	m, _ = press(t, atQuestion(0), "esc")
+	if !m.choosingPreset {
+		t.Errorf("Esc on the first question does not return to the presets")
+	}
+}
+
+func TestGoingBackSkipsQuestionsThatDoNotApply(t *testing.T) {
+	m := atQuestion(nodeVersionQuestion)
+	m.enabled["eslint"] = true
+	m, _ = press(t, m, "esc")
+	if m.index != toolsQuestion {
+		t.Errorf("index = %d, want the checklist", m.index)
+	}
+}
+
+func TestViewShowsTheProgress(t *testing.T) {
+	for index, want := range map[int]string{0: "[1/7] ", toolsQuestion: "[2/7] ", jiraKeyQuestion: "[5/7] "} {
+		if view := atQuestion(index).View(); !strings.HasPrefix(view, want) {
+			t.Errorf("question %d starts with %q, want %q", index, view[:8], want)
+		}
+	}
+}
+
+func TestSummaryWaitsForConfirmation(t *testing.T) {
+	inTempDir(t)
+	calls := stubSetupHooks(t)
+	m, _ := press(t, initialModel(), "enter", "enter", "enter", "enter")
+	summary := m.View()
+	for _, want := range []string{"Summary of your choices:", "Docroot: . (auto-detected)", "  + eslint\n", "  + editorconfig\n", "Press Enter to set up the hooks or Esc to cancel."} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "  + phpcs\n") {
+		t.Errorf("summary lists a tool that is not selected:\n%s", summary)
+	}
+	// Typing on the summary neither changes the answers nor starts the setup.
+	m, _ = press(t, m, "y", "space")
+	if len(*calls) != 0 || m.confirmed {
+		t.Fatalf("setup started before Enter")
+	}
+	m, cmd := press(t, m, "enter")
+	if !m.confirmed || len(*calls) != 0 {
+		t.Fatalf("confirmed=%v calls=%d: the setup must run as a command", m.confirmed, len(*calls))
+	}
+	runCmd(m, cmd)
+	if len(*calls) != 1 {
+		t.Errorf("setup ran %d times after confirming, want once", len(*calls))
+	}
+}
+
+func TestArrowsSetTheYesNoAnswer(t *testing.T) {
+	m := atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	m, _ = press(t, m, "left")
+	if !m.enabled["prettier-php"] || !strings.Contains(m.View(), "(•) Yes   ( ) No") {
+		t.Errorf("← does not select yes:\n%s", m.View())
+	}
+	m, _ = press(t, m, "right")
+	if m.enabled["prettier-php"] || !strings.Contains(m.View(), "( ) Yes   (•) No") {
+		t.Errorf("→ does not select no:\n%s", m.View())
+	}
+	m, _ = press(t, m, "left", "enter")
+	if !m.enabled["prettier-php"] || m.index != nodeVersionQuestion {
+		t.Errorf("prettierPHP=%v index=%d after confirming yes", m.enabled["prettier-php"], m.index)
+	}
+}
+
+func TestLeftGoesBackOnTextQuestions(t *testing.T) {
+	m := atQuestion(jiraKeyQuestion)
+	m.enabled["jira-prepare-commit-msg"] = true
+	m, _ = press(t, m, "left")
+	if m.index != toolsQuestion {
+		t.Errorf("index = %d, want the checklist", m.index)
+	}
+}
+
+func TestMissingDocrootStaysOnTheQuestion(t *testing.T) {
+	inTempDir(t)
+	m := typeText(atQuestion(0), "nowhere")
+	m, _ = press(t, m, "enter")
+	if m.index != 0 {
+		t.Fatalf("index = %d, want 0", m.index)
+	}
+	if !strings.Contains(m.View(), "Path nowhere not found, try again") {
+		t.Errorf("no inline error:\n%s", m.View())
+	}
+
+	// An empty answer still falls back to auto-detection.
+	if err := os.Mkdir("web", 0755); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = press(t, atQuestion(0), "enter")
+	if m.index != toolsQuestion || !slices.Equal(m.docroots, []string{"web"}) || !m.docrootDetected {
+		t.Errorf("index=%d docroots=%v docrootDetected=%v", m.index, m.docroots, m.docrootDetected)
+	}
+}
+
+func TestSetupDoneMsgEndsTheInstall(t *testing.T) {
+	m := atQuestion(len(questions))
+	m.installing = true
+	next, cmd := m.Update(setupDoneMsg{added: &artifacts{Files: []string{".lintstagedrc.json"}}})
+	m = next.(model)
+	if m.installing || !m.done || cmd == nil {
+		t.Fatalf("installing=%v done=%v", m.installing, m.done)
+	}
+	if view := m.View(); !strings.Contains(view, "✓ Git pre-commit hooks are set up.") || !strings.Contains(view, "+ .lintstagedrc.json") {
+		t.Errorf("unexpected frame:\n%s", view)
+	}
+
+	m = atQuestion(len(questions))
+	m.installing = true
+	next, _ = m.Update(setupDoneMsg{err: os.ErrPermission, notices: "Kept your .eslintrc.json\n"})
+	m = next.(model)
+	if view := m.View(); view != "Kept your .eslintrc.json\n\n✗ Setup failed: permission denied\n" {
+		t.Errorf("unexpected frame %q", view)
+	}
+}
+
+func TestKeysAreIgnoredWhileInstalling(t *testing.T) {
+	m := atQuestion(len(questions))
+	m.installing = true
+	for _, k := range []string{"ctrl+c", "q", "esc", "enter"} {
+		next, cmd := press(t, m, k)
+		if next.cancelled || !next.installing || cmd != nil {
+			t.Errorf("%s while installing: cancelled=%v installing=%v", k, next.cancelled, next.installing)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "Installing packages") {
+		t.Errorf("unexpected frame %q", view)
+	}
+}
+
+// stubLookPath makes every binary but the missing ones look installed.
+func stubLookPath(t *testing.T, missing ...string) {
+	t.Helper()
+	original := lookPath
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(missing, name) {
+			return "", exec.ErrNotFound
+		}
+		return "/usr/bin/" + name, nil
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+func TestGitRootNeedsARepository(t *testing.T) {
+	dir := inTempDir(t)
+	if _, err := gitRoot(); err == nil {
+		t.Fatalf("gitRoot succeeds outside a repository")
+	}
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	if err := os.Mkdir("sub", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("sub"); err != nil {
+		t.Fatal(err)
+	}
+	root, err := gitRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(root); got != want {
+		t.Errorf("gitRoot = %s, want %s", got, want)
+	}
+}
+
+func TestPromptsShareTheInput(t *testing.T) {
+	original := stdin
+	stdin = bufio.NewReader(strings.NewReader("y\nno\nyes\n"))
+	t.Cleanup(func() { stdin = original })
+	var answers []bool
+	for i := 0; i < 3; i++ {
+		answers = append(answers, confirm(""))
+	}
+	if !slices.Equal(answers, []bool{true, false, true}) {
+		t.Errorf("answers = %v, want each prompt to read its own line", answers)
+	}
+}
+
+func TestMissingBinaries(t *testing.T) {
+	stubLookPath(t, "pnpm")
+	if got := missingBinaries(pmBinaries(pnpm)...); !slices.Equal(got, []string{"pnpm"}) {
+		t.Errorf("missing = %v, want [pnpm]", got)
+	}
+	if got := missingBinaries(pmBinaries(npm)...); len(got) != 0 {
+		t.Errorf("missing = %v, want none", got)
+	}
+	// bun runs package binaries itself, so Node.js is not required.
+	if got := pmBinaries(bun); !slices.Equal(got, []string{"bun"}) {
+		t.Errorf("bun needs %v", got)
+	}
+}
+
+func TestYesNoAnswersAreValidated(t *testing.T) {
+	for answer, want := range map[string]bool{"y": true, "Y": true, "yes": true, "YES": true, "n": false, "N": false, "no": false, "No": false} {
+		m := atQuestion(prettierPHPQuestion)
+		m.enabled["prettier"], m.enabled["phpcs"] = true, true
+		m.enabled["prettier-php"] = !want
+		m = typeText(m, answer)
+		m, _ = press(t, m, "enter")
+		if m.enabled["prettier-php"] != want || m.index == prettierPHPQuestion {
+			t.Errorf("%q: prettierPHP=%v index=%d", answer, m.enabled["prettier-php"], m.index)
+		}
+	}
+
+	m := atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	m = typeText(m, "maybe")
+	m, _ = press(t, m, "enter")
+	if m.index != prettierPHPQuestion || !strings.Contains(m.View(), "Please answer y or n") {
+		t.Errorf("maybe: index=%d\n%s", m.index, m.View())
+	}
+}
+
+func TestWindowSizeWrapsTheQuestion(t *testing.T) {
+	next, _ := atQuestion(0).Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	m := next.(model)
+	if m.width != 40 || m.height != 20 {
+		t.Fatalf("width=%d height=%d", m.width, m.height)
+	}
+	view := m.View()
+	if !strings.Contains(view, "\n") {
+		t.Fatalf("the question is not wrapped:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if n := utf8.RuneCountInString(strings.TrimSuffix(line, " ")); n > 40 {
+			t.Errorf("line is %d wide: %q", n, line)
+		}
+	}
+	// The typed input follows the question's trailing space.
+	if !strings.HasSuffix(view, ": ") {
+		t.Errorf("the trailing space is lost: %q", view)
+	}
+	if got := wrapText("short", 0); got != "short" {
+		t.Errorf("wrapText without a width = %q", got)
+	}
+}
+
+func TestEmptyEnterKeepsTheDefault(t *testing.T) {
+	m := atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	if !strings.Contains(m.View(), "(y/N)") {
+		t.Errorf("the default is not shown:\n%s", m.View())
+	}
+	m, _ = press(t, m, "enter")
+	if m.enabled["prettier-php"] || m.index == prettierPHPQuestion {
+		t.Errorf("empty Enter: prettierPHP=%v index=%d, want the default no", m.enabled["prettier-php"], m.index)
+	}
+
+	// A choice made with the arrows is what Enter confirms.
+	m = atQuestion(prettierPHPQuestion)
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	m, _ = press(t, m, "left", "enter")
+	if !m.enabled["prettier-php"] {
+		t.Errorf("Enter after ← does not keep yes")
+	}
+}
+
+func TestDoneMessageListsTheArtifacts(t *testing.T) {
+	m := testModel()
+	m.packageManager = pnpm
+	m.enabled["eslint"], m.enabled["python"] = true, true
+	m.added = &artifacts{
+		Files: []string{".eslintrc.js", ".lintstagedrc.js"},
+		Hooks: []hookArtifact{{".husky/pre-commit", "pnpm exec lint-staged"}},
+	}
+	want := `✓ Git pre-commit hooks are set up.
+
+Created files:
+  + .eslintrc.js
+  + .lintstagedrc.js
+
+Added hooks:
+  + .husky/pre-commit: pnpm exec lint-staged
+
+Next steps:
+  - Install the Python tools: pip install -r requirements-dev.txt
+  - Run lint on the whole project: pnpm run lint
+  - Run lint:fix on the whole project: pnpm run lint:fix
+  - Commit a change to see the hooks run: git commit
+`
+	if got := m.doneMessage(); got != want {
+		t.Errorf("done message =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDetectDocroot(t *testing.T) {
+	inTempDir(t)
+	if got := detectDocroot(defaultDocrootCandidates); got != "." {
+		t.Errorf("without candidates: %s, want .", got)
+	}
+	// A file of the same name is not a docroot.
+	writeConfig(t, "docroot", "")
+	for _, dir := range []string{"web", "public"} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := detectDocroot(defaultDocrootCandidates); got != "web" {
+		t.Errorf("detected %s, want web", got)
+	}
+	if got := detectDocroot([]string{"public", "web"}); got != "public" {
+		t.Errorf("detected %s, want the first candidate public", got)
+	}
+
+	info := captureInfo(t)
+	m, _ := loadTestModel(t, "-eslint", "-docroot-candidates", "app,public")
+	if !slices.Equal(m.docroots, []string{"public"}) {
+		t.Errorf("docroots = %v", m.docroots)
+	}
+	if info.String() != "Auto-detected docroot: public\n" {
+		t.Errorf("the detected docroot is not reported: %q", info.String())
+	}
+}
+
+func TestSetupFromASubdirectoryUsesTheGitRoot(t *testing.T) {
+	dir := inTempDir(t)
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	writeConfig(t, configFileName, "docroot: .\ntools:\n  eslint: true\n")
+	writeConfig(t, "pnpm-lock.yaml", "")
+	if err := os.MkdirAll("src/app", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("src/app"); err != nil {
+		t.Fatal(err)
+	}
+	root, err := gitRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	// What main does after changing to the root: the repository's config
+	// and lockfile are found.
+	m, nonInteractive := loadTestModel(t)
+	if !nonInteractive || !m.enabled["eslint"] || detectPackageManager() != pnpm {
+		t.Errorf("from %s: nonInteractive=%v eslint=%v pm=%s", dir, nonInteractive, m.enabled["eslint"], detectPackageManager())
+	}
+}
+
+func TestCtrlUClearsTheInput(t *testing.T) {
+	m := atQuestion(0)
+	m = typeText(m, "some/long/docroot/path")
+	m, _ = press(t, m, "ctrl+u")
+	if m.docrootInput != "" {
+		t.Errorf("docrootInput = %q after Ctrl+U", m.docrootInput)
+	}
+	m, _ = press(t, m, "w", "e", "b")
+	if m.docrootInput != "web" {
+		t.Errorf("docrootInput = %q, want the input to continue after Ctrl+U", m.docrootInput)
+	}
+
+	m = atQuestion(prettierPHPQuestion)
+	m, _ = press(t, m, "y", "e", "s", "ctrl+u")
+	if m.answerBuffer != "" {
+		t.Errorf("answerBuffer = %q after Ctrl+U", m.answerBuffer)
+	}
+}
+
+func TestPasteIsCapturedWhole(t *testing.T) {
+	m := atQuestion(0)
+	m = typeText(m, "apps/web")
+	if m.docrootInput != "apps/web" {
+		t.Errorf("docrootInput = %q, want apps/web", m.docrootInput)
+	}
+
+	// Bracketed pastes set Paste, which msg.String() would render as
+	// "[apps/web]"; the trailing newline of a copied line is dropped.
+	m = atQuestion(0)
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("apps/my web\n"), Paste: true})
+	m = next.(model)
+	if m.docrootInput != "apps/my web" {
+		t.Errorf("docrootInput = %q after a bracketed paste, want apps/my web", m.docrootInput)
+	}
+	if m.index != 0 {
+		t.Errorf("the pasted newline answered the question")
+	}
+}
+
+func TestDocrootIsNormalized(t *testing.T) {
+	dir := inTempDir(t)
+	if err := os.MkdirAll(filepath.Join(dir, "apps", "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m := atQuestion(0)
+	m = typeText(m, "./apps/web/")
+	m, _ = press(t, m, "enter")
+	if !slices.Equal(m.docroots, []string{"apps/web"}) {
+		t.Fatalf("docroots = %q, want apps/web", m.docroots)
+	}
+	m.enabled["eslint"] = true
+	if _, ok := lintStagedConfig(m).get("apps/web/**/*.js"); !ok {
+		t.Errorf("globs are built from the raw docroot: %v", lintStagedConfig(m))
+	}
+
+	for input, want := range map[string][]string{
+		"web/":             {"web"},
+		"./web//":          {"web"},
+		" ./ ":             {"."},
+		"web/../docroot":   {"docroot"},
+		"./web/, apps//a/": {"web", "apps/a"},
+	} {
+		if got := parseDocroots(input); !slices.Equal(got, want) {
+			t.Errorf("parseDocroots(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestVerboseLogWaitsForTheWizardToExit(t *testing.T) {
+	inTempDir(t)
+	var stderr strings.Builder
+	originalLog := logOutput
+	logOutput = &stderr
+	t.Cleanup(func() { logOutput = originalLog })
+	original := setupHooks
+	setupHooks = func(m model) (*artifacts, error) {
+		m.logf("running npm install")
+		return &artifacts{}, nil
+	}
+	t.Cleanup(func() { setupHooks = original })
+
+	m := atQuestion(len(questions))
+	m.verbose = true
+	m = runCmd(m, runSetup(m))
+	if stderr.Len() != 0 {
+		t.Errorf("the log was written over the wizard: %q", stderr.String())
+	}
+	if m.setupLog != "running npm install\n" {
+		t.Errorf("setupLog = %q", m.setupLog)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var nodeVersionPattern = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+// parseNodeVersion accepts versions like 20, 20.11 or v20.11.1, as nvm does.
+func parseNodeVersion(value string) (string, error) {
+	version := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if !nodeVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid Node version %q (use e.g. 20 or 20.11.1)", value)
+	}
+	return version, nil
+}
+
+// detectNodeVersion returns the major version of the installed node, or ""
+// when node is not installed.
+func detectNodeVersion() string {
+	out, err := exec.Command("node", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	major, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(string(out)), "v"), ".")
+	return major
+}
+
+func generateNvmrc(version string) string {
+	return version + "\n"
+}
+
+// nodeEngines is the engines.node range of package.json. It is a lower bound
+// so that npm only warns about older versions.
+func nodeEngines(version string) string {
+	return ">=" + version
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNodeVersion(t *testing.T) {
+	for value, want := range map[string]string{"20": "20", " v20.11.1 ": "20.11.1", "18.19": "18.19"} {
+		got, err := parseNodeVersion(value)
+		if err != nil || got != want {
+			t.Errorf("parseNodeVersion(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"lts", "20.x", ">=20", ""} {
+		if _, err := parseNodeVersion(value); err == nil {
+			t.Errorf("parseNodeVersion(%q) accepts it", value)
+		}
+	}
+	if got := generateNvmrc("20.11.1"); got != "20.11.1\n" {
+		t.Errorf(".nvmrc = %q", got)
+	}
+
+	fakeCommands(t, map[string]string{"node": "echo v22.3.0"})
+	if got := detectNodeVersion(); got != "22" {
+		t.Errorf("detected %q, want the major version 22", got)
+	}
+	t.Setenv("PATH", t.TempDir())
+	if got := detectNodeVersion(); got != "" {
+		t.Errorf("detected %q without node", got)
+	}
+}
+
+func TestNodeVersionFiles(t *testing.T) {
+	inTempDir(t)
+	fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.yes = true
+	m.enabled["eslint"] = true
+	m.nodeVersion = "20"
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, ".nvmrc"); got != "20\n" {
+		t.Errorf(".nvmrc = %q", got)
+	}
+	var pkg struct {
+		Engines map[string]string `json:"engines"`
+	}
+	if err := json.Unmarshal([]byte(readFile(t, "package.json")), &pkg); err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Engines["node"] != ">=20" {
+		t.Errorf("engines = %v", pkg.Engines)
+	}
+}
+
+func TestWizardOnlyHintsAtTheDetectedNodeVersion(t *testing.T) {
+	inTempDir(t)
+	fakeCommands(t, map[string]string{"node": "echo v22.3.0"})
+	m, nonInteractive := loadTestModel(t)
+	if nonInteractive {
+		t.Fatal("no flags skip the wizard")
+	}
+	if m.nodeVersion != "" || m.detectedNode != "22" {
+		t.Fatalf("nodeVersion=%q detectedNode=%q, want only the hint", m.nodeVersion, m.detectedNode)
+	}
+	m.choosingPreset = false
+	m.index = nodeVersionQuestion
+	if view := m.View(); !strings.Contains(view, "(the installed Node is version 22)") {
+		t.Errorf("no hint:\n%s", view)
+	}
+	m, _ = press(t, m, "enter")
+	if m.nodeVersion != "" {
+		t.Errorf("an empty answer pins Node %q", m.nodeVersion)
+	}
+}
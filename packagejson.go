@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// packageScripts builds npm scripts that run the selected tools on the whole
+// project, next to the hooks that only check staged files.
+func packageScripts(m model) object {
+	var lint, fix, format []string
+	for _, t := range m.enabledTools() {
+		if t.scripts == nil {
+			continue
+		}
+		scripts := t.scripts(m)
+		if scripts.lint != "" {
+			lint = append(lint, scripts.lint)
+		}
+		if scripts.fix != "" {
+			fix = append(fix, scripts.fix)
+		}
+		if scripts.format != "" {
+			format = append(format, scripts.format)
+		}
+	}
+	scripts := object{}
+	if len(lint) > 0 {
+		scripts = append(scripts, field{"lint", strings.Join(lint, " && ")})
+	}
+	if len(fix) > 0 {
+		scripts = append(scripts, field{"lint:fix", strings.Join(fix, " && ")})
+	}
+	if len(format) > 0 {
+		scripts = append(scripts, field{"format", strings.Join(format, " && ")})
+	}
+	return scripts
+}
+
+func (m model) docrootList() string {
+	return strings.Join(m.docroots, " ")
+}
+
+// stylelintGlobs are the files stylelint checks in the whole project.
+func (m model) stylelintGlobs() string {
+	t, _ := findTool("stylelint")
+	return m.projectGlobs(m.toolGlob(t))
+}
+
+// projectGlobs quotes a glob matching pattern anywhere below each docroot, for
+// tools that expand globs themselves.
+func (m model) projectGlobs(pattern string) string {
+	globs := make([]string, len(m.docroots))
+	for i, docroot := range m.docroots {
+		glob := "**/" + pattern
+		if docroot != "." {
+			glob = docroot + "/" + glob
+		}
+		globs[i] = `"` + glob + `"`
+	}
+	return strings.Join(globs, " ")
+}
+
+// mergePackageJSON adds scripts and the -node-version engine to
+// package.json. Scripts and engines the project already defines are kept
+// unless -force is given, and every other key is left untouched. Scripts the
+// project did not have are recorded, so that -uninstall removes them again.
+func (m model) mergePackageJSON(scripts object) error {
+	var engines object
+	if m.nodeVersion != "" {
+		engines = object{{"node", nodeEngines(m.nodeVersion)}}
+	}
+	return m.updatePackageJSON(scripts, engines)
+}
+
+func (m model) updatePackageJSON(scripts, engines object) error {
+	if len(scripts) == 0 && len(engines) == 0 {
+		return nil
+	}
+	pkg := object{}
+	data, err := os.ReadFile("package.json")
+	if err == nil {
+		if pkg, err = parseJSONObject(data); err != nil {
+			return fmt.Errorf("parsing package.json: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading package.json: %w", err)
+	}
+	pkg, added, err := m.mergeObject(pkg, "scripts", "the %s script", scripts)
+	if err != nil {
+		return err
+	}
+	if pkg, _, err = m.mergeObject(pkg, "engines", "engines.%s", engines); err != nil {
+		return err
+	}
+	if m.dryRun {
+		m.infof("[dry-run] would update package.json\n")
+		return nil
+	}
+	if err := os.WriteFile("package.json", []byte(renderJSON(pkg)), 0644); err != nil {
+		return fmt.Errorf("writing package.json: %w", err)
+	}
+	m.created.addScripts(added...)
+	m.added.addScripts(added...)
+	return nil
+}
+
+// mergeObject adds fields to the object at key in pkg, keeping the ones it
+// already has unless -force is given, and returns the keys that are new.
+// describe names a field in messages.
+func (m model) mergeObject(pkg object, key, describe string, fields object) (object, []string, error) {
+	if len(fields) == 0 {
+		return pkg, nil, nil
+	}
+	existing := object{}
+	if value, ok := pkg.get(key); ok {
+		if existing, ok = value.(object); !ok {
+			return nil, nil, fmt.Errorf("package.json: %s is not an object", key)
+		}
+	}
+	var added []string
+	for _, f := range fields {
+		if _, ok := existing.get(f.key); ok {
+			if !m.force {
+				m.infof("Skipping "+describe+": package.json already defines it (use -force to overwrite)\n", f.key)
+				continue
+			}
+		} else {
+			added = append(added, f.key)
+		}
+		existing = existing.set(f.key, f.value)
+	}
+	return pkg.set(key, existing), added, nil
+}
+
+// removePackageScript drops a script the setup added from package.json.
+func (m model) removePackageScript(name string) error {
+	data, err := os.ReadFile("package.json")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading package.json: %w", err)
+	}
+	pkg, err := parseJSONObject(data)
+	if err != nil {
+		return fmt.Errorf("parsing package.json: %w", err)
+	}
+	value, _ := pkg.get("scripts")
+	scripts, _ := value.(object)
+	if _, ok := scripts.get(name); !ok {
+		return nil
+	}
+	if m.dryRun {
+		m.infof("[dry-run] would remove the %s script from package.json\n", name)
+		return nil
+	}
+	if err := os.WriteFile("package.json", []byte(renderJSON(pkg.set("scripts", scripts.remove(name)))), 0644); err != nil {
+		return fmt.Errorf("writing package.json: %w", err)
+	}
+	return nil
+}
+
+// ensurePackageJSON creates a package.json in a project without one, which
+// the package managers need before they can install devDependencies.
+func (m model) ensurePackageJSON(pm packageManager) error {
+	if _, err := os.Stat("package.json"); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	m.infof("No package.json found, creating one\n")
+	if err := m.initPackageJSON(pm); err != nil || m.dryRun {
+		return err
+	}
+	// The package.json goes again with a rollback or -uninstall.
+	m.created.addFile("package.json")
+	m.added.addFile("package.json")
+	return nil
+}
+
+func (m model) initPackageJSON(pm packageManager) error {
+	if init := pmInit(pm); init != nil {
+		return m.runCommand(init[0], init[1:]...)
+	}
+	// bun init also scaffolds an entry point and a tsconfig, so bun projects
+	// get a minimal package.json written directly.
+	if m.dryRun {
+		m.infof("[dry-run] would write package.json\n")
+		return nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	pkg := object{{"name", strings.ToLower(filepath.Base(dir))}, {"private", true}}
+	if err := os.WriteFile("package.json", []byte(renderJSON(pkg)), 0644); err != nil {
+		return fmt.Errorf("writing package.json: %w", err)
+	}
+	return nil
+}
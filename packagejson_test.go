@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestMergeScriptsKeepsOtherKeys(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	writeConfig(t, "package.json", `{
+  "name": "site",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "webpack",
+    "lint": "custom-lint"
+  },
+  "devDependencies": {
+    "webpack": "^5.0.0"
+  }
+}
+`)
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"] = true, true
+	if err := m.mergePackageJSON(packageScripts(m)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "name": "site",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "webpack",
+    "lint": "custom-lint",
+    "lint:fix": "eslint . --fix",
+    "format": "prettier --write ."
+  },
+  "devDependencies": {
+    "webpack": "^5.0.0"
+  }
+}
+`
+	if got := readFile(t, "package.json"); got != want {
+		t.Errorf("package.json =\n%s\nwant\n%s", got, want)
+	}
+
+	m.force = true
+	if err := m.mergePackageJSON(packageScripts(m)); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := parseJSONObject([]byte(readFile(t, "package.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scripts, _ := pkg.get("scripts")
+	if lint, _ := scripts.(object).get("lint"); lint != "eslint ." {
+		t.Errorf("-force leaves lint as %v", lint)
+	}
+}
+
+func TestMergeScriptsRejectsBrokenPackageJSON(t *testing.T) {
+	inTempDir(t)
+	writeConfig(t, "package.json", `{"scripts": "lint"}`)
+	m := testModel()
+	m.enabled["eslint"] = true
+	if err := m.mergePackageJSON(packageScripts(m)); err == nil {
+		t.Errorf("scripts that are not an object are overwritten")
+	}
+	if data, _ := os.ReadFile("package.json"); string(data) != `{"scripts": "lint"}` {
+		t.Errorf("package.json was changed: %s", data)
+	}
+}
+
+func TestEnsurePackageJSON(t *testing.T) {
+	inTempDir(t)
+	log := fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.created, m.added = &artifacts{}, &artifacts{}
+	if err := m.ensurePackageJSON(npm); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, log); got != "npm init -y\n" {
+		t.Errorf("commands = %q, want npm init -y", got)
+	}
+	if _, err := os.Stat("package.json"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.added.Files, []string{"package.json"}) || !slices.Equal(m.created.Files, []string{"package.json"}) {
+		t.Errorf("the created package.json is not tracked: added=%v created=%v", m.added.Files, m.created.Files)
+	}
+
+	// An existing package.json is reused as it is.
+	inTempDir(t)
+	log = fakePackageManagers(t)
+	writeConfig(t, "package.json", `{"name": "site"}`)
+	m.created, m.added = &artifacts{}, &artifacts{}
+	if err := m.ensurePackageJSON(npm); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(log); err == nil {
+		t.Errorf("commands ran for an existing package.json: %q", data)
+	}
+	if got := readFile(t, "package.json"); got != `{"name": "site"}` || len(m.added.Files) != 0 {
+		t.Errorf("package.json = %q, added = %v", got, m.added.Files)
+	}
+}
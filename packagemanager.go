@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type packageManager string
+
+const (
+	npm       packageManager = "npm"
+	pnpm      packageManager = "pnpm"
+	yarn      packageManager = "yarn"
+	yarnBerry packageManager = "yarn-berry"
+	bun       packageManager = "bun"
+)
+
+var lockfiles = []struct {
+	name string
+	pm   packageManager
+}{
+	{"pnpm-lock.yaml", pnpm},
+	{"yarn.lock", yarn},
+	{"bun.lockb", bun},
+	// Text lockfile written by bun 1.2 and later.
+	{"bun.lock", bun},
+	{"package-lock.json", npm},
+}
+
+func parsePackageManager(value string) (packageManager, error) {
+	switch pm := packageManager(value); pm {
+	case npm, pnpm, yarn, yarnBerry, bun:
+		return pm, nil
+	}
+	return "", fmt.Errorf("unknown package manager %q", value)
+}
+
+func detectPackageManager() packageManager {
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(lockfile.name); err != nil {
+			continue
+		}
+		// Yarn 2+ (berry) projects carry a .yarnrc.yml; classic Yarn uses .yarnrc.
+		if lockfile.pm == yarn {
+			if _, err := os.Stat(".yarnrc.yml"); err == nil {
+				return yarnBerry
+			}
+		}
+		return lockfile.pm
+	}
+	return npm
+}
+
+// pmBinaries lists the binaries the package manager needs. bun runs package
+// binaries itself, even those with a node shebang, so it does not need Node.js.
+func pmBinaries(pm packageManager) []string {
+	if pm == bun {
+		return []string{"bun"}
+	}
+	return []string{"node", pmInstall(pm, nil)[0]}
+}
+
+func pmInstall(pm packageManager, pkgs []string) []string {
+	switch pm {
+	case pnpm:
+		return append([]string{"pnpm", "add", "-D"}, pkgs...)
+	case yarn, yarnBerry:
+		return append([]string{"yarn", "add", "-D"}, pkgs...)
+	case bun:
+		return append([]string{"bun", "add", "-d"}, pkgs...)
+	}
+	return append([]string{"npm", "install", "--save-dev"}, pkgs...)
+}
+
+// pmInit creates a package.json with defaults, or returns nil when the
+// package manager has no such command.
+func pmInit(pm packageManager) []string {
+	switch pm {
+	case pnpm:
+		return []string{"pnpm", "init"}
+	case yarn, yarnBerry:
+		return []string{"yarn", "init", "-y"}
+	case bun:
+		return nil
+	}
+	return []string{"npm", "init", "-y"}
+}
+
+// pmExactFlag makes pmInstall save the exact versions asked for instead of
+// a range.
+func pmExactFlag(pm packageManager) string {
+	switch pm {
+	case yarn, yarnBerry, bun:
+		return "--exact"
+	}
+	return "--save-exact"
+}
+
+// pmCleanInstall installs exactly what the lockfile lists, as CI should.
+func pmCleanInstall(pm packageManager) []string {
+	switch pm {
+	case pnpm:
+		return []string{"pnpm", "install", "--frozen-lockfile"}
+	case yarn:
+		return []string{"yarn", "install", "--frozen-lockfile"}
+	case yarnBerry:
+		return []string{"yarn", "install", "--immutable"}
+	case bun:
+		return []string{"bun", "install", "--frozen-lockfile"}
+	}
+	return []string{"npm", "ci"}
+}
+
+func pmRemove(pm packageManager, pkgs []string) []string {
+	switch pm {
+	case pnpm:
+		return append([]string{"pnpm", "remove"}, pkgs...)
+	case yarn, yarnBerry:
+		return append([]string{"yarn", "remove"}, pkgs...)
+	case bun:
+		return append([]string{"bun", "remove"}, pkgs...)
+	}
+	return append([]string{"npm", "uninstall"}, pkgs...)
+}
+
+func pmRun(pm packageManager, script string) []string {
+	switch pm {
+	case pnpm:
+		return []string{"pnpm", "run", script}
+	case yarn, yarnBerry:
+		return []string{"yarn", "run", script}
+	case bun:
+		return []string{"bun", "run", script}
+	}
+	return []string{"npm", "run", script}
+}
+
+// pmExec runs a binary from a locally installed package. Berry's dlx would
+// download a throwaway copy instead, so exec is used there; classic Yarn has
+// no exec and runs package binaries through run.
+func pmExec(pm packageManager, args ...string) []string {
+	switch pm {
+	case pnpm:
+		return append([]string{"pnpm", "exec"}, args...)
+	case yarn:
+		return append([]string{"yarn", "run"}, args...)
+	case yarnBerry:
+		return append([]string{"yarn", "exec"}, args...)
+	case bun:
+		return append([]string{"bunx"}, args...)
+	}
+	return append([]string{"npx"}, args...)
+}
+
+func composerRequire(pkgs []string) []string {
+	return append([]string{"composer", "require", "--dev"}, pkgs...)
+}
+
+func composerRemove(pkgs []string) []string {
+	return append([]string{"composer", "remove", "--dev"}, pkgs...)
+}
+
+func composerAllowPlugin(name string) []string {
+	return []string{"composer", "config", "--no-plugins", "allow-plugins." + name, "true"}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestCommandsPerPackageManager(t *testing.T) {
+	tests := []struct {
+		pm      packageManager
+		install []string
+		exec    []string
+	}{
+		{npm, []string{"npm", "install", "--save-dev", "husky"}, []string{"npx", "husky"}},
+		{pnpm, []string{"pnpm", "add", "-D", "husky"}, []string{"pnpm", "exec", "husky"}},
+	}
+	for _, tt := range tests {
+		if got := pmInstall(tt.pm, []string{"husky"}); !slices.Equal(got, tt.install) {
+			t.Errorf("pmInstall(%s) = %v, want %v", tt.pm, got, tt.install)
+		}
+		if got := pmExec(tt.pm, "husky"); !slices.Equal(got, tt.exec) {
+			t.Errorf("pmExec(%s) = %v, want %v", tt.pm, got, tt.exec)
+		}
+	}
+	if pm, err := parsePackageManager("pnpm"); err != nil || pm != pnpm {
+		t.Errorf("parsePackageManager(pnpm) = %q, %v", pm, err)
+	}
+	if _, err := parsePackageManager("pip"); err == nil {
+		t.Errorf("parsePackageManager accepts pip")
+	}
+}
+
+func TestYarnLockSelectsYarnCommands(t *testing.T) {
+	inTempDir(t)
+	if err := os.WriteFile("yarn.lock", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pm := detectPackageManager()
+	if pm != yarn {
+		t.Fatalf("detected %q, want yarn", pm)
+	}
+	if got := pmInstall(pm, []string{"husky"}); !slices.Equal(got, []string{"yarn", "add", "-D", "husky"}) {
+		t.Errorf("install = %v", got)
+	}
+	// Classic Yarn has no exec, so binaries run through yarn run.
+	if got := commitMsgHookCommand(pm); got != `yarn run commitlint --edit "$1"` {
+		t.Errorf("commit-msg hook = %q", got)
+	}
+
+	if err := os.WriteFile(".yarnrc.yml", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pm = detectPackageManager()
+	if pm != yarnBerry {
+		t.Fatalf("detected %q with .yarnrc.yml, want yarn-berry", pm)
+	}
+	// dlx would download a copy, so berry runs the installed one with exec.
+	if got := commitMsgHookCommand(pm); got != `yarn exec commitlint --edit "$1"` {
+		t.Errorf("berry commit-msg hook = %q", got)
+	}
+}
+
+func TestDetectPackageManagerFromLockfile(t *testing.T) {
+	tests := map[string]packageManager{
+		"":                  npm,
+		"pnpm-lock.yaml":    pnpm,
+		"yarn.lock":         yarn,
+		"bun.lockb":         bun,
+		"bun.lock":          bun,
+		"package-lock.json": npm,
+	}
+	for lockfile, want := range tests {
+		inTempDir(t)
+		if lockfile != "" {
+			if err := os.WriteFile(lockfile, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := detectPackageManager(); got != want {
+			t.Errorf("with %q: detected %q, want %q", lockfile, got, want)
+		}
+	}
+}
+
+func TestBunCommands(t *testing.T) {
+	if got := pmInstall(bun, []string{"husky"}); !slices.Equal(got, []string{"bun", "add", "-d", "husky"}) {
+		t.Errorf("install = %v", got)
+	}
+	if got := huskyInitCommand(bun, 9); !slices.Equal(got, []string{"bunx", "husky"}) {
+		t.Errorf("husky init = %v", got)
+	}
+	if got := pmExactFlag(bun); got != "--exact" {
+		t.Errorf("exact flag = %s", got)
+	}
+	// bun init scaffolds more than a package.json, so it is written directly.
+	if got := pmInit(bun); got != nil {
+		t.Errorf("init = %v", got)
+	}
+	inTempDir(t)
+	captureInfo(t)
+	m := testModel()
+	if err := m.ensurePackageJSON(bun); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := parseJSONObject([]byte(readFile(t, "package.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if private, _ := pkg.get("private"); private != true {
+		t.Errorf("package.json = %v", pkg)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var presets = map[string]func(m *model){
+	"none": func(m *model) {},
+	"drupal": func(m *model) {
+		m.enabled["phpcs"] = true
+		m.enabled["eslint"] = true
+		m.enabled["stylelint"] = true
+		m.enabled["secretlint"] = true
+	},
+	"react": func(m *model) {
+		m.enabled["eslint"] = true
+		m.react = true
+		m.enabled["prettier"] = true
+		m.enabled["stylelint"] = true
+	},
+}
+
+// presetNames lists the presets alphabetically, with "none" first so the
+// wizard defaults to not pre-selecting anything.
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		if name != "none" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{"none"}, names...)
+}
+
+func applyPreset(m *model, name string) error {
+	apply, ok := presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(presetNames(), ", "))
+	}
+	// react is only ever set by a preset, so choosing another one, e.g.
+	// after going back in the wizard, must not keep it.
+	m.react = false
+	apply(m)
+	m.preset = name
+	return nil
+}
+
+func (m model) updatePresetChoice(msg tea.KeyMsg) model {
+	switch msg.String() {
+	case "up", "k":
+		if m.presetCursor > 0 {
+			m.presetCursor--
+		}
+	case "down", "j":
+		if m.presetCursor < len(presets)-1 {
+			m.presetCursor++
+		}
+	case "enter":
+		m.choosePreset(presetNames()[m.presetCursor])
+		m.choosingPreset = false
+	}
+	return m
+}
+
+// choosePreset applies the preset chosen in the wizard to the tools chosen
+// before it, with flags or in a config, so that it selects the same tools as
+// -preset. Choosing again after going back starts over, and "none" starts
+// from the checklist defaults.
+func (m *model) choosePreset(name string) {
+	for _, t := range toolRegistry() {
+		if value, ok := m.presetBase[t.name]; ok {
+			m.enabled[t.name] = value
+		} else {
+			m.enabled[t.name] = name == "none" && t.preselected
+		}
+	}
+	// presetNames only holds known presets, so this cannot fail.
+	_ = applyPreset(m, name)
+}
+
+func (m model) presetView() string {
+	var b strings.Builder
+	b.WriteString("Choose a preset to pre-select tools:\n\n")
+	for i, name := range presetNames() {
+		cursor := "  "
+		if i == m.presetCursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%s\n", cursor, name)
+	}
+	b.WriteString("\n(↑/↓ to choose, Enter to confirm)\n")
+	return b.String()
+}
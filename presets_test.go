@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// selectedTools lists the names of the tools the model selects.
+func selectedTools(m model) []string {
+	var names []string
+	for _, t := range m.enabledTools() {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func TestDrupalPreset(t *testing.T) {
+	inTempDir(t)
+	m, _ := loadTestModel(t, "-preset", "drupal", "-docroot", ".")
+	if got := strings.Join(selectedTools(m), ","); got != "eslint,stylelint,secretlint,phpcs" {
+		t.Errorf("drupal selects %s", got)
+	}
+	config := generatePhpcsConfig(m)
+	for _, want := range []string{`<rule ref="Drupal"/>`, `<rule ref="DrupalPractice"/>`} {
+		if !strings.Contains(config, want) {
+			t.Errorf("phpcs.xml does not contain %s:\n%s", want, config)
+		}
+	}
+
+	// Flags after the preset override it.
+	m, _ = loadTestModel(t, "-preset", "drupal", "-docroot", ".", "-eslint=false")
+	if m.enabled["eslint"] || !m.enabled["phpcs"] {
+		t.Errorf("eslint=%v phpcs=%v after -eslint=false", m.enabled["eslint"], m.enabled["phpcs"])
+	}
+}
+
+func TestWizardPresetMatchesTheFlag(t *testing.T) {
+	inTempDir(t)
+	m, _ := loadTestModel(t)
+	m, _ = press(t, m, "down")
+	m, _ = press(t, m, "enter")
+	if m.preset != "drupal" {
+		t.Fatalf("preset = %q", m.preset)
+	}
+	if got := strings.Join(selectedTools(m), ","); got != "eslint,stylelint,secretlint,phpcs" {
+		t.Errorf("choosing drupal in the wizard selects %s", got)
+	}
+	// Going back to none restores the checklist defaults.
+	m, _ = press(t, m, "esc", "up", "enter")
+	if got := strings.Join(selectedTools(m), ","); got != "eslint,prettier,stylelint,secretlint,editorconfig" {
+		t.Errorf("choosing none after drupal selects %s", got)
+	}
+}
+
+func TestReactPreset(t *testing.T) {
+	m := testModel()
+	if err := applyPreset(&m, "react"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(selectedTools(m), ","); got != "eslint,prettier,stylelint" || !m.react {
+		t.Errorf("react selects %s, react=%v", got, m.react)
+	}
+	extends, _ := eslintConfig(m).get("extends")
+	if !slices.Contains(extends.([]string), "plugin:react/recommended") {
+		t.Errorf("extends = %v", extends)
+	}
+	npm, _ := m.packageLists(huskyBackend{})
+	if !slices.Contains(npm, "eslint-plugin-react") || !slices.Contains(npm, "eslint-plugin-react-hooks") {
+		t.Errorf("packages = %v", npm)
+	}
+
+	// Choosing another preset afterwards drops the React plugins.
+	if err := applyPreset(&m, "drupal"); err != nil {
+		t.Fatal(err)
+	}
+	if m.react {
+		t.Errorf("react stays set after choosing drupal")
+	}
+	if err := applyPreset(&m, "vue"); err == nil {
+		t.Errorf("an unknown preset is accepted")
+	}
+}
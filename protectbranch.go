@@ -0,0 +1,37 @@
+package main
+
+import "strings"
+
+const protectBranchScript = ".protect-branch.sh"
+
+var defaultProtectedBranches = []string{"main", "master"}
+
+// protectBranchCheck gets the branches as arguments, so changing them only
+// changes the hook command. Commits without a branch, e.g. during a rebase,
+// are let through.
+const protectBranchCheck = `#!/bin/sh
+# Rejects commits to the branches given as arguments.
+# Usage: sh .protect-branch.sh <branches...>
+branch=$(git symbolic-ref --short HEAD 2>/dev/null) || exit 0
+for protected in "$@"; do
+  if [ "$branch" = "$protected" ]; then
+    echo "Commits to $branch are not allowed, create a branch first: git switch -c <name>" >&2
+    echo "Use git commit --no-verify to commit anyway." >&2
+    exit 1
+  fi
+done
+`
+
+func (m model) protectBranchCommand() string {
+	return formatCommand("sh", append([]string{protectBranchScript}, m.protectedBranches...)...)
+}
+
+func parseBranches(input string) []string {
+	var branches []string
+	for _, branch := range strings.Split(input, ",") {
+		if branch = strings.TrimSpace(branch); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestProtectBranchGuard(t *testing.T) {
+	inTempDir(t)
+	fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.yes = true
+	m.enabled["protect-branches"] = true
+	m.protectedBranches = parseBranches(" main, release ,")
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	// The guard runs before lint-staged rewrites the staged files.
+	if got, want := readFile(t, ".husky/pre-commit"), "sh .protect-branch.sh main release\nnpx lint-staged\n"; got != want {
+		t.Errorf(".husky/pre-commit = %q, want %q", got, want)
+	}
+	if got := readFile(t, protectBranchScript); got != protectBranchCheck {
+		t.Errorf("%s = %q", protectBranchScript, got)
+	}
+	for _, want := range []string{`branch=$(git symbolic-ref --short HEAD 2>/dev/null) || exit 0`, `for protected in "$@"; do`, "git commit --no-verify"} {
+		if !strings.Contains(protectBranchCheck, want) {
+			t.Errorf("the guard does not contain %q", want)
+		}
+	}
+
+	if err := exec.Command("git", "init", "-q").Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	m.skipCI = true
+	if err := (gitHooksBackend{}).setup(m, npm, m.hookCommands(npm)); err != nil {
+		t.Fatal(err)
+	}
+	want := "#!/bin/sh\n" + ciBailOut + "\nsh .protect-branch.sh main release\nnpx lint-staged\n"
+	if got := readFile(t, ".git/hooks/pre-commit"); got != want {
+		t.Errorf(".git/hooks/pre-commit = %q, want %q", got, want)
+	}
+	for branch, allowed := range map[string]bool{"main": false, "release": false, "feature": true} {
+		if err := exec.Command("git", "symbolic-ref", "HEAD", "refs/heads/"+branch).Run(); err != nil {
+			t.Fatal(err)
+		}
+		err := exec.Command("sh", protectBranchScript, "main", "release").Run()
+		if (err == nil) != allowed {
+			t.Errorf("commit to %s: err=%v, want allowed=%v", branch, err, allowed)
+		}
+	}
+}
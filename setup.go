@@ -0,0 +1,1001 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type configFile struct {
+	name    string
+	content string
+}
+
+// hookCommand is a command run by a git hook other than the lint-staged
+// pre-commit checks, such as commit-msg.
+type hookCommand struct {
+	hook    string
+	command string
+}
+
+// setupGitHooks sets up the hooks and returns what this run added to the
+// project.
+func setupGitHooks(m model) (_ *artifacts, err error) {
+	created, err := loadArtifacts(artifactsFileName)
+	if err != nil {
+		return nil, err
+	}
+	m.created = created
+	m.added = &artifacts{}
+	// A failed setup removes what it added so far instead of leaving the
+	// project half configured. Once the artifacts are saved the setup is
+	// complete, and a failing -verify leaves it in place to fix the errors.
+	rollback := true
+	defer func() {
+		if err != nil && rollback {
+			m.rollback()
+		}
+	}()
+	backend := m.hookBackend()
+	if m.enabled["eslint"] && !m.react {
+		m.react = hasDependency("react")
+	}
+	installPackages, composerPackages := m.packageLists(backend)
+	pm := m.packageManager
+	if pm == "" {
+		pm = detectPackageManager()
+	}
+	var files []configFile
+	for _, t := range m.enabledTools() {
+		if t.configContent != "" {
+			files = append(files, configFile{m.configFile(t.name), t.configContent})
+		}
+		if t.files != nil {
+			files = append(files, t.files(m, pm)...)
+		}
+	}
+	if pythonRequirements := m.pythonRequirements(); len(pythonRequirements) > 0 {
+		files = append(files, configFile{"requirements-dev.txt", strings.Join(pythonRequirements, "\n") + "\n"})
+	}
+	if m.nodeVersion != "" {
+		files = append(files, configFile{".nvmrc", generateNvmrc(m.nodeVersion)})
+	}
+	binaries := backend.binaries()
+	if len(composerPackages) > 0 {
+		binaries = append(binaries, "composer")
+	}
+	for _, t := range m.enabledTools() {
+		if t.binary != "" {
+			binaries = append(binaries, t.binary)
+		}
+	}
+	if missing := missingBinaries(binaries...); len(missing) > 0 {
+		return nil, fmt.Errorf("missing required tools: %s", strings.Join(missing, ", "))
+	}
+	if !m.confirmPackages(newPackages(installPackages, hasDependency), newPackages(composerPackages, hasComposerDependency)) {
+		return nil, fmt.Errorf("installing the packages was declined")
+	}
+	var ignored []string
+	if len(installPackages) > 0 {
+		ignored = append(ignored, "node_modules/")
+	}
+	if len(composerPackages) > 0 {
+		ignored = append(ignored, "vendor/")
+	}
+	if err := m.ensureGitignore(ignored); err != nil {
+		return nil, err
+	}
+	stop := m.timings.start("writing files")
+	for _, file := range files {
+		if reason, ok := rootConfigs[file.name]; ok && m.configDir != "" {
+			m.infof("Keeping %s in the project root, as %s\n", file.name, reason)
+		}
+		write := m.writeFile
+		if strings.HasPrefix(file.content, "#!") {
+			// Scripts like the license header check can be run by hand.
+			write = m.writeExecutableFile
+		}
+		if err := write(file.name, file.content); err != nil {
+			return nil, err
+		}
+	}
+	stop()
+	if len(composerPackages) > 0 {
+		stop := m.timings.start("composer install")
+		var commands [][]string
+		if m.enabled["phpcs"] && m.phpcsRules().installer {
+			// drupal/coder and WPCS register their standards with phpcs
+			// through this Composer plugin, which has to be allowed to run.
+			commands = append(commands, composerAllowPlugin("dealerdirect/phpcodesniffer-composer-installer"))
+		}
+		commands = append(commands, composerRequire(m.pinned(composerPackages, ":")))
+		added := newPackages(composerPackages, hasComposerDependency)
+		for _, command := range commands {
+			err := m.runWithRetry(installAttempts, func() error {
+				return m.runCommand(command[0], command[1:]...)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !m.dryRun {
+			m.created.addComposerPackages(added...)
+			m.added.addComposerPackages(added...)
+		}
+		stop()
+	}
+
+	if len(installPackages) > 0 {
+		stop := m.timings.start(string(pm) + " install")
+		if err := m.ensurePackageJSON(pm); err != nil {
+			return nil, err
+		}
+		install := pmInstall(pm, m.pinned(installPackages, "@"))
+		if m.pinsVersions() {
+			install = append(install, pmExactFlag(pm))
+		}
+		added := newPackages(installPackages, hasDependency)
+		err := m.runWithRetry(installAttempts, func() error {
+			return m.runCommand(install[0], install[1:]...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !m.dryRun {
+			m.created.addPackages(added...)
+			m.added.addPackages(added...)
+		}
+		stop()
+	}
+
+	hooks := m.hookCommands(pm)
+	stop = m.timings.start(m.backendName() + " setup")
+	if err := backend.setup(m, pm, hooks); err != nil {
+		return nil, err
+	}
+	stop()
+	stop = m.timings.start("package.json")
+	if err := m.mergePackageJSON(packageScripts(m)); err != nil {
+		return nil, err
+	}
+	stop()
+	if !m.dryRun {
+		if err := m.created.save(artifactsFileName); err != nil {
+			return nil, err
+		}
+	}
+	rollback = false
+	if m.verify {
+		stop := m.timings.start("verify")
+		command := backend.verifyCommand(m, pm)
+		if err := m.runCommand(command[0], command[1:]...); err != nil {
+			return nil, fmt.Errorf("verifying the pre-commit hook: %w", err)
+		}
+		stop()
+	}
+	return m.added, nil
+}
+
+// hookCommands lists the commands run by hooks other than the lint-staged
+// pre-commit hook.
+func (m model) hookCommands(pm packageManager) []hookCommand {
+	var hooks []hookCommand
+	for _, t := range m.enabledTools() {
+		if t.hooks != nil {
+			hooks = append(hooks, t.hooks(m, pm)...)
+		}
+	}
+	for _, step := range ciSteps(m, pm) {
+		if m.prePush[step.tool] {
+			hooks = append(hooks, hookCommand{"pre-push", step.run})
+		}
+	}
+	return hooks
+}
+
+// packageLists lists the npm and Composer packages the selected tools need.
+func (m model) packageLists(backend hookBackend) (npm, composer []string) {
+	npm = backend.packages()
+	for _, t := range m.enabledTools() {
+		npm = append(npm, t.packages...)
+		composer = append(composer, t.composer...)
+		if t.morePackages != nil {
+			moreNpm, moreComposer := t.morePackages(m)
+			npm = append(npm, moreNpm...)
+			composer = append(composer, moreComposer...)
+		}
+	}
+	return npm, composer
+}
+
+// packagesToInstall lists the packages the project does not have yet.
+func (m model) packagesToInstall() (npm, composer []string) {
+	if m.enabled["eslint"] && !m.react {
+		m.react = hasDependency("react")
+	}
+	npm, composer = m.packageLists(m.hookBackend())
+	return newPackages(npm, hasDependency), newPackages(composer, hasComposerDependency)
+}
+
+// pythonRequirements lists the Python tools, which are installed with pip
+// rather than npm.
+func (m model) pythonRequirements() []string {
+	var requirements []string
+	for _, t := range m.enabledTools() {
+		requirements = append(requirements, t.pip...)
+	}
+	return requirements
+}
+
+// confirmInstall asks a yes/no question on the terminal. It is a variable so
+// that the prompt can be answered without a terminal.
+var confirmInstall = confirm
+
+// confirmPackages lists the packages about to be installed and asks to go
+// ahead. The wizard shows them in its summary instead, and -yes and -dry-run
+// skip the question.
+func (m model) confirmPackages(npm, composer []string) bool {
+	if len(npm) == 0 && len(composer) == 0 {
+		return true
+	}
+	if m.yes || m.confirmed || m.dryRun {
+		return true
+	}
+	fmt.Print(packageList(npm, composer))
+	return confirmInstall("Install them? (y/n): ")
+}
+
+func packageList(npm, composer []string) string {
+	var b strings.Builder
+	if len(npm) > 0 {
+		fmt.Fprintf(&b, "These npm packages will be installed as devDependencies:\n  %s\n", strings.Join(npm, "\n  "))
+	}
+	if len(composer) > 0 {
+		fmt.Fprintf(&b, "These Composer packages will be required with --dev:\n  %s\n", strings.Join(composer, "\n  "))
+	}
+	return b.String()
+}
+
+// withoutReplacedTools turns off the tools another chosen tool replaces, so
+// that they do not fight over the same files.
+func (m model) withoutReplacedTools() model {
+	enabled := maps.Clone(m.enabled)
+	for _, t := range m.enabledTools() {
+		for _, replaced := range t.replaces {
+			enabled[replaced] = false
+		}
+	}
+	m.enabled = enabled
+	return m
+}
+
+// latestHuskyMajor is assumed when the installed version cannot be detected,
+// since husky is installed without a version.
+const latestHuskyMajor = 9
+
+func (m model) detectHuskyMajor(pm packageManager) int {
+	command := pmExec(pm, "husky", "--version")
+	output, err := m.commandOutput(command[0], command[1:]...)
+	if err != nil {
+		return latestHuskyMajor
+	}
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(strings.TrimSpace(output), "v"), ".", 2)[0])
+	if err != nil {
+		return latestHuskyMajor
+	}
+	return major
+}
+
+// Husky 9 dropped `husky install` and `husky add`: running `husky` sets up
+// git's hooksPath and hooks are plain shell scripts in .husky/.
+func huskyInitCommand(pm packageManager, major int) []string {
+	if major >= 9 {
+		return pmExec(pm, "husky")
+	}
+	return pmExec(pm, "husky", "install")
+}
+
+// huskyPrepareScript is the npm prepare script that runs husky's setup on
+// install. npm scripts find husky in node_modules/.bin without npx.
+func huskyPrepareScript(major int) string {
+	if major >= 9 {
+		return "husky"
+	}
+	return "husky install"
+}
+
+func huskyHookHeader(major int) string {
+	if major >= 9 {
+		return ""
+	}
+	return "#!/usr/bin/env sh\n. \"$(dirname -- \"$0\")/_/husky.sh\"\n\n"
+}
+
+// ensureHookCommand appends command to the hook at path, creating the hook
+// with header if needed. Hooks that already run the command are left alone,
+// so running the setup again does not duplicate it. The CI bail-out and then
+// the protected branch guard go first, also into hooks that already run
+// other commands, so that nothing rewrites the staged files of a commit the
+// guard rejects.
+func (m model) ensureHookCommand(path, header, command string) error {
+	content := header
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		for _, line := range strings.Split(string(existing), "\n") {
+			if strings.TrimSpace(line) == command {
+				return nil
+			}
+		}
+		content = string(existing)
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if m.dryRun {
+		m.infof("[dry-run] would add %q to %s\n", command, path)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if command == ciBailOut || command == m.protectBranchCommand() {
+		content = prependHookCommand(content, command)
+	} else {
+		content += command + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	m.created.addHook(path, command)
+	m.added.addHook(path, command)
+	return os.Chmod(path, 0755)
+}
+
+// prependHookCommand inserts command before the hook's other commands, after
+// its shebang and the CI bail-out.
+func prependHookCommand(content, command string) string {
+	var head string
+	if strings.HasPrefix(content, "#!") {
+		shebang, rest, _ := strings.Cut(content, "\n")
+		head, content = shebang+"\n", rest
+	}
+	if command != ciBailOut {
+		if rest, ok := strings.CutPrefix(content, ciBailOut+"\n"); ok {
+			head, content = head+ciBailOut+"\n", rest
+		}
+	}
+	return head + command + "\n" + content
+}
+
+// ensureGitignore appends the entries .gitignore does not have yet, creating
+// it when needed. An entry counts as present with or without its leading or
+// trailing slash.
+func (m model) ensureGitignore(entries []string) error {
+	existing, err := os.ReadFile(".gitignore")
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading .gitignore: %w", err)
+	}
+	created := os.IsNotExist(err)
+	present := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.Trim(strings.TrimSpace(line), "/")] = true
+	}
+	content := string(existing)
+	var added []string
+	for _, entry := range entries {
+		if !present[strings.Trim(entry, "/")] {
+			present[strings.Trim(entry, "/")] = true
+			added = append(added, entry)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if m.dryRun {
+		m.infof("[dry-run] would add %s to .gitignore\n", strings.Join(added, ", "))
+		return nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(added, "\n") + "\n"
+	m.logf("adding %s to .gitignore", strings.Join(added, ", "))
+	if err := os.WriteFile(".gitignore", []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing .gitignore: %w", err)
+	}
+	// A .gitignore this run created goes again with a rollback or
+	// -uninstall, like the config files.
+	if created {
+		m.created.addFile(".gitignore")
+		m.added.addFile(".gitignore")
+	}
+	return nil
+}
+
+// generateEditorConfig uses the two-space indentation of the Drupal coding
+// standards and the prettier defaults, and the usual conventions of Go, Python
+// and Makefiles. PHP follows the PHPCS standard when it is another one.
+func generateEditorConfig(m model) string {
+	php := ""
+	if m.enabled["phpcs"] {
+		php = m.phpcsRules().editorconfig
+	}
+	return `root = true
+
+[*]
+charset = utf-8
+end_of_line = lf
+indent_style = space
+indent_size = 2
+insert_final_newline = true
+trim_trailing_whitespace = true
+
+[*.py]
+indent_size = 4
+
+[*.go]
+indent_style = tab
+` + php + `
+[Makefile]
+indent_style = tab
+
+[*.md]
+trim_trailing_whitespace = false
+`
+}
+
+const golangciConfig = `run:
+  timeout: 5m
+
+linters:
+  enable:
+    - errcheck
+    - gosimple
+    - govet
+    - ineffassign
+    - staticcheck
+    - unused
+`
+
+// black has no setup.cfg support and its defaults are fine, so only isort and
+// flake8 are configured to agree with it.
+const pythonSetupConfig = `[flake8]
+max-line-length = 88
+extend-ignore = E203
+exclude = .git,__pycache__,.venv,node_modules,vendor
+
+[isort]
+profile = black
+`
+
+const yamllintConfig = `extends: relaxed
+
+ignore: |
+  node_modules/
+  vendor/
+`
+
+const rubocopConfig = `AllCops:
+  NewCops: enable
+  Exclude:
+    - 'node_modules/**/*'
+    - 'vendor/**/*'
+    - 'db/schema.rb'
+`
+
+const markdownlintConfig = `{
+  "default": true,
+  "MD013": false,
+  "MD033": false,
+  "MD041": false
+}
+`
+
+const sqlFormatterConfig = `{
+  "language": "sql",
+  "tabWidth": 2,
+  "keywordCase": "upper"
+}
+`
+
+const gitleaksConfig = `title = "gitleaks config"
+
+[extend]
+useDefault = true
+
+[allowlist]
+description = "Dependencies installed by package managers"
+paths = [
+  '''node_modules/''',
+  '''vendor/''',
+]
+`
+
+// DL3008 and DL3018 ask to pin apt and apk package versions, which most
+// projects do not do.
+const hadolintConfig = `ignored:
+  - DL3008
+  - DL3018
+`
+
+// biomeConfig leaves ignoring files to .gitignore, which also keeps
+// node_modules and vendor out.
+var biomeConfig = object{
+	{"vcs", object{
+		{"enabled", true},
+		{"clientKind", "git"},
+		{"useIgnoreFile", true},
+	}},
+	{"formatter", object{
+		{"enabled", true},
+		{"indentStyle", "space"},
+	}},
+	{"linter", object{
+		{"enabled", true},
+		{"rules", object{{"recommended", true}}},
+	}},
+}
+
+var secretlintConfig = object{
+	{"rules", []object{
+		{{"id", "@secretlint/secretlint-rule-preset-recommend"}},
+	}},
+}
+
+// cspellConfig starts the project dictionary with the names of the tools the
+// setup adds, which cspell would otherwise flag in the generated configs.
+var cspellConfig = object{
+	{"version", "0.2"},
+	{"language", "en"},
+	{"words", []string{"commitlint", "drupal", "eslint", "gitleaks", "golangci", "husky", "lintstaged", "markdownlint", "phpcbf", "phpcs", "phpstan", "secretlint", "stylelint"}},
+	{"ignorePaths", []string{"node_modules/**", "vendor/**", "package-lock.json", "pnpm-lock.yaml", "yarn.lock", "composer.lock"}},
+}
+
+var commitlintConfig = object{
+	{"extends", []string{"@commitlint/config-conventional"}},
+}
+
+var validateBranchNameConfig = object{
+	{"pattern", `^(main|master|develop)$|^(feature|bugfix|hotfix|release)\/.+$|^[A-Z]+-\d+.*$`},
+	{"errorMsg", "Branch names must look like feature/..., bugfix/..., hotfix/..., release/... or start with a ticket number like JIRA-123."},
+}
+
+// jira-prepare-commit-msg reads its settings through cosmiconfig, which looks
+// for this rc file next to package.json.
+func jiraPrepareCommitConfig(m model) object {
+	return object{
+		{"messagePattern", "[$J] $M"},
+		{"jiraTicketPattern", jiraTicketPattern(m.jiraKey)},
+		{"commentChar", "#"},
+		{"isConventionalCommit", false},
+		{"allowEmptyCommitMessage", false},
+	}
+}
+
+var jiraProjectKey = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// jiraTicketPattern turns a project key into a pattern matching its ticket
+// numbers. Anything else is taken to be a pattern already.
+func jiraTicketPattern(key string) string {
+	switch {
+	case key == "":
+		return `([A-Z]+-\d+)`
+	case jiraProjectKey.MatchString(key):
+		return "(" + key + `-\d+)`
+	}
+	return key
+}
+
+func commitMsgHookCommand(pm packageManager) string {
+	return strings.Join(pmExec(pm, "commitlint", "--edit", `"$1"`), " ")
+}
+
+func prepareCommitMsgHookCommand(pm packageManager) string {
+	return strings.Join(pmExec(pm, "jira-prepare-commit-msg", `"$1"`), " ")
+}
+
+func generateEslintConfig(m model) string {
+	return m.renderConfig(eslintConfig(m))
+}
+
+func eslintConfig(m model) object {
+	extends := []string{"eslint:recommended"}
+	if m.react {
+		extends = append(extends, "plugin:react/recommended", "plugin:react-hooks/recommended")
+	}
+	if m.enabled["prettier"] {
+		// eslint-config-prettier turns off the rules prettier's formatting
+		// would break, so it has to come last.
+		extends = append(extends, "prettier")
+	}
+	parserOptions := object{
+		{"ecmaVersion", "latest"},
+		{"sourceType", "module"},
+	}
+	if m.react {
+		parserOptions = append(parserOptions, field{"ecmaFeatures", object{{"jsx", true}}})
+	}
+	config := object{
+		{"root", true},
+		{"env", object{
+			{"browser", true},
+			{"node", true},
+			{"es2022", true},
+		}},
+		{"parserOptions", parserOptions},
+		{"extends", extends},
+	}
+	if m.react {
+		config = append(config, field{"settings", object{{"react", object{{"version", "detect"}}}}})
+	}
+	return append(config, field{"rules", object{}})
+}
+
+// phpPaths lists the directories PHP tools analyse when run on the whole
+// project. Drupal core and contrib code is left out for the Drupal preset.
+func (m model) phpPaths() []string {
+	if m.preset != "drupal" {
+		return m.docroots
+	}
+	var paths []string
+	for _, docroot := range m.docroots {
+		paths = append(paths, docroot+"/modules/custom", docroot+"/themes/custom")
+	}
+	return paths
+}
+
+// phpcsStandard is a coding standard PHPCS can check, with the Composer
+// packages that provide it.
+type phpcsStandard struct {
+	title      string
+	packages   []string
+	installer  bool
+	rules      []string
+	extensions []string
+	// dataExtensions are the extensions of files the standard checks that
+	// are not PHP code, which prettier leaves alone.
+	dataExtensions []string
+	// editorconfig holds the [*.php] section matching the standard's
+	// indentation, when it differs from the two-space default.
+	editorconfig string
+	// prettier holds the prettier-php options matching the standard, or nil
+	// when prettier cannot agree with it.
+	prettier object
+}
+
+// phpcsStandards are keyed by their -phpcs-standard names.
+var phpcsStandards = map[string]phpcsStandard{
+	"drupal": {
+		title:          "Drupal",
+		packages:       []string{"drupal/coder"},
+		installer:      true,
+		rules:          []string{"Drupal", "DrupalPractice"},
+		extensions:     []string{"php", "module", "inc", "install", "test", "profile", "theme", "info", "yml"},
+		dataExtensions: []string{"info", "yml"},
+		// The Drupal standard checks against indents with two spaces and
+		// keeps braces on the same line.
+		prettier: object{{"tabWidth", 2}, {"braceStyle", "1tbs"}},
+	},
+	"psr12": {
+		title:        "PSR-12",
+		rules:        []string{"PSR12"},
+		extensions:   []string{"php"},
+		editorconfig: "\n[*.php]\nindent_size = 4\n",
+		prettier:     object{{"tabWidth", 4}, {"braceStyle", "psr-2"}},
+	},
+	"wordpress": {
+		title:        "WordPress",
+		packages:     []string{"wp-coding-standards/wpcs"},
+		installer:    true,
+		rules:        []string{"WordPress"},
+		extensions:   []string{"php"},
+		editorconfig: "\n[*.php]\nindent_style = tab\n",
+	},
+}
+
+var phpcsStandardNames = []string{"drupal", "psr12", "wordpress"}
+
+func parsePhpcsStandard(value string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(value))
+	if _, ok := phpcsStandards[name]; !ok {
+		return "", fmt.Errorf("unknown PHPCS standard %q (use %s)", value, strings.Join(phpcsStandardNames, ", "))
+	}
+	return name, nil
+}
+
+func (m model) phpcsRules() phpcsStandard {
+	if standard, ok := phpcsStandards[m.phpcsStandard]; ok {
+		return standard
+	}
+	return phpcsStandards["drupal"]
+}
+
+// phpcsExtensions are the extensions PHPCS checks: the -phpcs-ext ones, or
+// else those of the standard.
+func (m model) phpcsExtensions() []string {
+	if custom := m.extensions["phpcs"]; len(custom) > 0 {
+		return custom
+	}
+	return m.phpcsRules().extensions
+}
+
+// phpCodeExtensions are the phpcsExtensions of PHP code, which prettier
+// formats when it formats PHP.
+func (m model) phpCodeExtensions() []string {
+	var code []string
+	for _, extension := range m.phpcsExtensions() {
+		if !slices.Contains(m.phpcsRules().dataExtensions, extension) {
+			code = append(code, extension)
+		}
+	}
+	return code
+}
+
+func generatePhpcsConfig(m model) string {
+	standard := m.phpcsRules()
+	config := "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"
+	config += fmt.Sprintf("<ruleset name=\"%s\">\n", standard.title)
+	config += fmt.Sprintf("  <description>PHP CodeSniffer configuration for %s.</description>\n", standard.title)
+	for _, path := range m.phpPaths() {
+		config += fmt.Sprintf("  <file>%s</file>\n", m.fromConfigDir(path))
+	}
+	config += fmt.Sprintf("  <arg name=\"extensions\" value=\"%s\"/>\n", strings.Join(m.phpcsExtensions(), ","))
+	config += "  <exclude-pattern>*/node_modules/*</exclude-pattern>\n"
+	config += "  <exclude-pattern>*/vendor/*</exclude-pattern>\n"
+	for _, rule := range standard.rules {
+		config += fmt.Sprintf("  <rule ref=\"%s\"/>\n", rule)
+	}
+	config += "</ruleset>\n"
+	return config
+}
+
+// formatsPHPWithPrettier reports whether prettier formats PHP. It only does
+// so next to PHPCS, which it is configured to agree with, and not for
+// standards it cannot agree with.
+func (m model) formatsPHPWithPrettier() bool {
+	return m.enabled["prettier-php"] && m.enabled["prettier"] && m.enabled["phpcs"] && m.phpcsRules().prettier != nil
+}
+
+func prettierConfig(m model) object {
+	if !m.formatsPHPWithPrettier() {
+		return object{}
+	}
+	return object{
+		{"plugins", []string{"@prettier/plugin-php"}},
+		{"overrides", []object{
+			{
+				// Extensions like .module need the parser named.
+				{"files", extensionsGlob(m.phpCodeExtensions())},
+				{"options", append(object{{"parser", "php"}}, m.phpcsRules().prettier...)},
+			},
+		}},
+	}
+}
+
+func stylelintConfig(m model) object {
+	extends := []string{"stylelint-config-standard-scss"}
+	if m.enabled["prettier"] {
+		// Reports prettier's formatting as stylelint errors, so prettier
+		// owns the formatting. It has to come last to win over other configs.
+		extends = append(extends, "stylelint-prettier/recommended")
+	}
+	return object{
+		{"extends", extends},
+	}
+}
+
+func tsconfig(m model) object {
+	config := object{
+		{"compilerOptions", object{
+			{"target", "ES2022"},
+			{"module", "ESNext"},
+			{"moduleResolution", "Bundler"},
+			{"strict", true},
+			{"skipLibCheck", true},
+			{"noEmit", true},
+		}},
+	}
+	var include []string
+	for _, docroot := range m.docroots {
+		if docroot != "." {
+			include = append(include, docroot)
+		}
+	}
+	if len(include) == len(m.docroots) && len(include) > 0 {
+		config = append(config, field{"include", include})
+	}
+	return config
+}
+
+func generatePhpstanConfig(m model) string {
+	config := "parameters:\n"
+	config += fmt.Sprintf("  level: %d\n", m.phpstanLevel)
+	config += "  paths:\n"
+	for _, path := range m.phpPaths() {
+		config += fmt.Sprintf("    - %s\n", m.fromConfigDir(path))
+	}
+	config += "  excludePaths:\n"
+	config += "    - '*/node_modules/*'\n"
+	config += "    - '*/vendor/*'\n"
+	return config
+}
+
+// packageManagerField returns the packageManager field of package.json, e.g.
+// pnpm@9.1.0, or "" when there is none.
+func packageManagerField() string {
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+	return pkg.PackageManager
+}
+
+func hasDependency(name string) bool {
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	_, inDeps := pkg.Dependencies[name]
+	_, inDevDeps := pkg.DevDependencies[name]
+	return inDeps || inDevDeps
+}
+
+func (m model) writeFile(filename, content string) error {
+	return m.writeFileMode(filename, content, 0644)
+}
+
+// writeExecutableFile writes a script like writeFile, making it executable
+// also when it is overwritten.
+func (m model) writeExecutableFile(filename, content string) error {
+	return m.writeFileMode(filename, content, 0755)
+}
+
+func (m model) writeFileMode(filename, content string, perm os.FileMode) error {
+	_, err := os.Stat(filename)
+	if err == nil && !m.force {
+		m.infof("Skipping %s: file already exists (use -force to overwrite)\n", filename)
+		return nil
+	}
+	existed := err == nil
+	m.logf("writing %s", filename)
+	if m.dryRun {
+		m.infof("[dry-run] would write %s (%d bytes)\n", filename, len(content))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(filename), err)
+	}
+	if err := os.WriteFile(filename, []byte(content), perm); err != nil {
+		return fmt.Errorf("writing %s: %w", filename, err)
+	}
+	// WriteFile keeps the mode of existing files.
+	if perm&0111 != 0 {
+		if err := os.Chmod(filename, perm); err != nil {
+			return fmt.Errorf("making %s executable: %w", filename, err)
+		}
+	}
+	if !existed {
+		m.created.addFile(filename)
+		m.added.addFile(filename)
+	}
+	return nil
+}
+
+func (m model) runCommand(cmdName string, args ...string) error {
+	m.logf("running %s", formatCommand(cmdName, args...))
+	if m.dryRun {
+		m.infof("[dry-run] would run: %s\n", formatCommand(cmdName, args...))
+		return nil
+	}
+	cmd := exec.Command(cmdName, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("running %s: %w\n%s", formatCommand(cmdName, args...), err, output)
+	}
+	return nil
+}
+
+// installAttempts is how often package installs are tried, as registries
+// fail now and then.
+const installAttempts = 3
+
+// retryDelay is the wait after the first failed attempt, doubled after each
+// further one. sleep is a variable so that the wait can be skipped.
+var (
+	retryDelay = 2 * time.Second
+	sleep      = time.Sleep
+)
+
+func (m model) runWithRetry(attempts int, fn func() error) error {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		m.logf("attempt %d of %d failed, retrying in %s: %v", attempt, attempts, delay, err)
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+func (m model) commandOutput(cmdName string, args ...string) (string, error) {
+	if m.dryRun {
+		return "", fmt.Errorf("not running %s in dry-run mode", cmdName)
+	}
+	m.logf("running %s", formatCommand(cmdName, args...))
+	output, err := exec.Command(cmdName, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("running %s: %w", formatCommand(cmdName, args...), err)
+	}
+	return string(output), nil
+}
+
+// logOutput receives the -verbose log. It is stderr so that the log does not
+// mix with output meant for scripts. The wizard collects the log in the
+// model's verboseLog instead, as stderr would write over its frames.
+var logOutput io.Writer = os.Stderr
+
+func (m model) logf(format string, args ...any) {
+	if !m.verbose {
+		return
+	}
+	if m.verboseLog != nil {
+		fmt.Fprintf(m.verboseLog, format+"\n", args...)
+		return
+	}
+	fmt.Fprintf(logOutput, format+"\n", args...)
+}
+
+// infoOutput receives progress and informational messages, which -quiet
+// turns off. Errors go to stderr regardless. The wizard collects them in
+// the model's notices instead.
+var infoOutput io.Writer = os.Stdout
+
+func (m model) infof(format string, args ...any) {
+	if m.quiet {
+		return
+	}
+	if m.notices != nil {
+		fmt.Fprintf(m.notices, format, args...)
+		return
+	}
+	fmt.Fprintf(infoOutput, format, args...)
+}
+
+func formatCommand(cmdName string, args ...string) string {
+	parts := []string{cmdName}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t\"'") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
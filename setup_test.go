@@ -0,0 +1,900 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEslintConfigIsValid(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"] = true
+	config, err := parseJSConfig(generateEslintConfig(m))
+	if err != nil {
+		t.Fatalf("the generated .eslintrc.js does not parse: %v", err)
+	}
+	extends, ok := config.get("extends")
+	if !ok || !slices.Equal(extends.([]any), []any{"eslint:recommended"}) {
+		t.Errorf("extends = %v", extends)
+	}
+
+	m.configFormat = formatJSON
+	m.react, m.enabled["prettier"] = true, true
+	var parsed struct {
+		Extends []string `json:"extends"`
+	}
+	if err := json.Unmarshal([]byte(generateEslintConfig(m)), &parsed); err != nil {
+		t.Fatalf("the generated .eslintrc.json does not parse: %v", err)
+	}
+	want := []string{"eslint:recommended", "plugin:react/recommended", "plugin:react-hooks/recommended", "prettier"}
+	if !slices.Equal(parsed.Extends, want) {
+		t.Errorf("extends = %v, want %v", parsed.Extends, want)
+	}
+}
+
+func TestEslintStaysOnTheEslintrcMajor(t *testing.T) {
+	m := testModel()
+	if got := m.pinned([]string{"eslint", "prettier"}, "@"); !slices.Equal(got, []string{"eslint@8", "prettier"}) {
+		t.Errorf("default install = %v, want eslint@8", got)
+	}
+	m.pin = true
+	if got := m.pinned([]string{"eslint"}, "@"); !slices.Equal(got, []string{"eslint@" + pinnedVersions["eslint"]}) {
+		t.Errorf("-pin install = %v", got)
+	}
+}
+
+// captureInfo collects what infof prints.
+func captureInfo(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var b bytes.Buffer
+	original := infoOutput
+	infoOutput = &b
+	t.Cleanup(func() { infoOutput = original })
+	return &b
+}
+
+func TestDryRunHasNoSideEffects(t *testing.T) {
+	dir := inTempDir(t)
+	stubLookPath(t)
+	info := captureInfo(t)
+	m := testModel()
+	m.dryRun, m.yes = true, true
+	m.enabled["eslint"], m.enabled["prettier"], m.enabled["phpcs"] = true, true, true
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("dry run created %s", entry.Name())
+	}
+	for _, want := range []string{"[dry-run] would write .lintstagedrc.js", "[dry-run] would run: npm install --save-dev"} {
+		if !strings.Contains(info.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, info.String())
+		}
+	}
+	if err := m.runCommand("false"); err != nil {
+		t.Errorf("runCommand ran a command in dry-run mode: %v", err)
+	}
+	if !strings.HasPrefix(m.doneMessage(), "Dry run finished") {
+		t.Errorf("unexpected done message:\n%s", m.doneMessage())
+	}
+}
+
+func TestExistingFileIsKeptWithoutForce(t *testing.T) {
+	inTempDir(t)
+	info := captureInfo(t)
+	if err := os.WriteFile(".eslintrc.js", []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := testModel()
+	m.added, m.created = &artifacts{}, &artifacts{}
+	if err := m.writeFile(".eslintrc.js", "generated"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(".eslintrc.js"); string(data) != "custom" {
+		t.Errorf("the file was overwritten: %q", data)
+	}
+	if !strings.Contains(info.String(), "Skipping .eslintrc.js: file already exists") {
+		t.Errorf("no notice about the skipped file: %q", info.String())
+	}
+	if len(m.added.Files) != 0 {
+		t.Errorf("a kept file is recorded as added: %v", m.added.Files)
+	}
+
+	m.force = true
+	if err := m.writeFile(".eslintrc.js", "generated"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(".eslintrc.js"); string(data) != "generated" {
+		t.Errorf("-force does not overwrite: %q", data)
+	}
+	// The project had the file, so uninstalling must not delete it.
+	if len(m.added.Files) != 0 {
+		t.Errorf("an overwritten file is recorded as added: %v", m.added.Files)
+	}
+}
+
+func TestRunCommandReturnsErrors(t *testing.T) {
+	m := testModel()
+	err := m.runCommand("sh", "-c", "echo broken >&2; exit 3")
+	if err == nil {
+		t.Fatal("a failing command returned no error")
+	}
+	if !strings.Contains(err.Error(), "running sh -c") || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("error does not name the command and its output: %v", err)
+	}
+	if err := m.runCommand("pre-committer-no-such-binary"); err == nil {
+		t.Errorf("a missing binary returned no error")
+	}
+	if err := m.runCommand("true"); err != nil {
+		t.Errorf("a passing command failed: %v", err)
+	}
+}
+
+// fakeCommands puts shell scripts named after commands first on the PATH.
+func fakeCommands(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// fakeNpx answers husky --version with 9, and does nothing for the other
+// binaries it is asked to run.
+const fakeNpx = `if [ "$1 $2" = "husky --version" ]; then echo 9.1.7; fi`
+
+// setupHusky runs the husky backend with the model's hooks.
+func setupHusky(t *testing.T, m model) {
+	t.Helper()
+	fakeCommands(t, map[string]string{"npx": fakeNpx})
+	if err := (huskyBackend{}).setup(m, npm, m.hookCommands(npm)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCommitlintHook(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["commitlint"] = true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/commit-msg"); got != "npx commitlint --edit \"$1\"\n" {
+		t.Errorf(".husky/commit-msg = %q", got)
+	}
+	if !strings.Contains(m.renderConfig(commitlintConfig), "@commitlint/config-conventional") {
+		t.Errorf("the commitlint config does not extend the conventional rules")
+	}
+	npm, _ := m.packageLists(huskyBackend{})
+	if !slices.Contains(npm, "@commitlint/cli") || !slices.Contains(npm, "@commitlint/config-conventional") {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestJiraPrepareCommitMsgHook(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["jira-prepare-commit-msg"] = true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/prepare-commit-msg"); got != "npx jira-prepare-commit-msg \"$1\"\n" {
+		t.Errorf(".husky/prepare-commit-msg = %q", got)
+	}
+	if _, err := os.Stat(".prepare-commit-msg"); !os.IsNotExist(err) {
+		t.Errorf("the root .prepare-commit-msg stub is written")
+	}
+}
+
+func TestValidateBranchNameHookAndPattern(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["validate-branch-name"] = true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-push"); got != "npx validate-branch-name\n" {
+		t.Errorf(".husky/pre-push = %q", got)
+	}
+	value, _ := validateBranchNameConfig.get("pattern")
+	pattern := regexp.MustCompile(value.(string))
+	for branch, valid := range map[string]bool{
+		"main":               true,
+		"feature/login":      true,
+		"bugfix/crash":       true,
+		"JIRA-123-login":     true,
+		"my-branch":          false,
+		"feature/":           false,
+		"jira-123-lowercase": false,
+	} {
+		if pattern.MatchString(branch) != valid {
+			t.Errorf("pattern matches %q: %v, want %v", branch, !valid, valid)
+		}
+	}
+}
+
+func TestSecretlintUsesTheRecommendedPreset(t *testing.T) {
+	m := testModel()
+	m.configFormat = formatJSON
+	var config struct {
+		Rules []struct {
+			ID string `json:"id"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(m.renderConfig(secretlintConfig)), &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Rules) != 1 || config.Rules[0].ID != "@secretlint/secretlint-rule-preset-recommend" {
+		t.Errorf("rules = %+v", config.Rules)
+	}
+	m.enabled["secretlint"] = true
+	if npm, _ := m.packageLists(huskyBackend{}); !slices.Contains(npm, "@secretlint/secretlint-rule-preset-recommend") {
+		t.Errorf("the preset is not installed: %v", npm)
+	}
+}
+
+func TestEnsureHookCommandDoesNotDuplicate(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	for i := 0; i < 2; i++ {
+		if err := m.ensureHookCommand(".husky/pre-commit", "", "npx lint-staged"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readFile(t, ".husky/pre-commit"); got != "npx lint-staged\n" {
+		t.Errorf(".husky/pre-commit = %q, want a single npx lint-staged", got)
+	}
+
+	// Commands of an existing hook are kept, and the new one is appended.
+	if err := os.WriteFile(".husky/pre-push", []byte("npm test"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.ensureHookCommand(".husky/pre-push", "", "npx validate-branch-name"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readFile(t, ".husky/pre-push"); got != "npm test\nnpx validate-branch-name\n" {
+		t.Errorf(".husky/pre-push = %q", got)
+	}
+}
+
+func packageScript(t *testing.T, name string) any {
+	t.Helper()
+	pkg, err := parseJSONObject([]byte(readFile(t, "package.json")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scripts, _ := pkg.get("scripts")
+	value, _ := scripts.(object).get(name)
+	return value
+}
+
+func TestHuskyAddsThePrepareScript(t *testing.T) {
+	inTempDir(t)
+	captureInfo(t)
+	writeConfig(t, "package.json", `{"scripts": {"build": "webpack"}}`)
+	m := testModel()
+	m.created, m.added = &artifacts{}, &artifacts{}
+	setupHusky(t, m)
+	if got := packageScript(t, "prepare"); got != "husky" {
+		t.Errorf("prepare = %v, want husky", got)
+	}
+	if got := packageScript(t, "build"); got != "webpack" {
+		t.Errorf("build = %v, want it kept", got)
+	}
+	if !slices.Equal(m.added.Scripts, []string{"prepare"}) {
+		t.Errorf("the prepare script is not tracked: %v", m.added.Scripts)
+	}
+
+	// A prepare script the project has is kept unless -force is given.
+	writeConfig(t, "package.json", `{"scripts": {"prepare": "npm run build"}}`)
+	m.created, m.added = &artifacts{}, &artifacts{}
+	setupHusky(t, m)
+	if got := packageScript(t, "prepare"); got != "npm run build" || len(m.added.Scripts) != 0 {
+		t.Errorf("prepare = %v, added = %v", got, m.added.Scripts)
+	}
+	m.force = true
+	fakeCommands(t, map[string]string{"npx": `if [ "$1 $2" = "husky --version" ]; then echo 8.0.3; fi`})
+	if err := (huskyBackend{}).setup(m, npm, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := packageScript(t, "prepare"); got != "husky install" {
+		t.Errorf("prepare with husky 8 and -force = %v, want husky install", got)
+	}
+}
+
+func TestHuskyVersionBranching(t *testing.T) {
+	if got := huskyInitCommand(npm, 9); !slices.Equal(got, []string{"npx", "husky"}) {
+		t.Errorf("husky 9 init = %v", got)
+	}
+	if got := huskyInitCommand(npm, 8); !slices.Equal(got, []string{"npx", "husky", "install"}) {
+		t.Errorf("husky 8 init = %v", got)
+	}
+	if got := huskyHookHeader(9); got != "" {
+		t.Errorf("husky 9 hooks start with %q", got)
+	}
+	if got := huskyHookHeader(8); !strings.Contains(got, `. "$(dirname -- "$0")/_/husky.sh"`) {
+		t.Errorf("husky 8 hooks do not source husky.sh: %q", got)
+	}
+
+	m := testModel()
+	fakeCommands(t, map[string]string{"npx": "echo v8.0.3"})
+	if got := m.detectHuskyMajor(npm); got != 8 {
+		t.Errorf("detected husky %d, want 8", got)
+	}
+	fakeCommands(t, map[string]string{"npx": "exit 1"})
+	if got := m.detectHuskyMajor(npm); got != latestHuskyMajor {
+		t.Errorf("detected husky %d without husky, want %d", got, latestHuskyMajor)
+	}
+}
+
+func TestPhpstanConfigAndGlob(t *testing.T) {
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["phpstan"] = true
+	m.phpstanLevel = 6
+	want := "parameters:\n  level: 6\n  paths:\n    - web\n  excludePaths:\n    - '*/node_modules/*'\n    - '*/vendor/*'\n"
+	if got := generatePhpstanConfig(m); got != want {
+		t.Errorf("phpstan.neon =\n%s\nwant\n%s", got, want)
+	}
+	if got := commandsFor(t, m, "web/**/*.php"); !slices.Equal(got, []string{"vendor/bin/phpstan analyse --no-progress"}) {
+		t.Errorf("*.php runs %v", got)
+	}
+	npm, composer := m.packageLists(gitHooksBackend{})
+	if !slices.Equal(composer, []string{"phpstan/phpstan"}) || slices.Contains(npm, "phpstan") {
+		t.Errorf("npm = %v, composer = %v", npm, composer)
+	}
+}
+
+// fakePackageManagers puts npm, npx and composer on the PATH that only log
+// how they are run. It returns the log file.
+func fakePackageManagers(t *testing.T) string {
+	t.Helper()
+	log := filepath.Join(t.TempDir(), "commands.log")
+	t.Setenv("COMMANDS_LOG", log)
+	fakeCommands(t, map[string]string{
+		"npm":      `echo "npm $*" >> "$COMMANDS_LOG"; if [ "$1" = init ]; then echo '{}' > package.json; fi`,
+		"npx":      `echo "npx $*" >> "$COMMANDS_LOG"; ` + fakeNpx,
+		"composer": `echo "composer $*" >> "$COMMANDS_LOG"`,
+	})
+	return log
+}
+
+func TestPHPToolsComeFromComposer(t *testing.T) {
+	inTempDir(t)
+	log := fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.yes = true
+	m.enabled["phpcs"] = true
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	commands := readFile(t, log)
+	if !strings.Contains(commands, "composer require --dev squizlabs/php_codesniffer drupal/coder\n") {
+		t.Errorf("composer is not run for PHPCS:\n%s", commands)
+	}
+	if !strings.Contains(commands, "npm install --save-dev husky lint-staged\n") {
+		t.Errorf("npm installs more than the hook manager:\n%s", commands)
+	}
+	got := commandsFor(t, m, drupalGlob)
+	if len(got) != 2 || got[1] != "vendor/bin/phpcs --standard=phpcs.xml" || !strings.Contains(got[0], "vendor/bin/phpcbf") {
+		t.Errorf("%s runs %v", drupalGlob, got)
+	}
+}
+
+func TestTypescriptHook(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["typescript"] = true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-push"); got != "npx tsc --noEmit\n" {
+		t.Errorf(".husky/pre-push = %q", got)
+	}
+	// tsc checks the whole project, so it is not run on staged files.
+	if len(lintStagedConfig(m)) != 0 {
+		t.Errorf("tsc is in the lint-staged config: %v", lintStagedConfig(m))
+	}
+	var config struct {
+		CompilerOptions map[string]any `json:"compilerOptions"`
+	}
+	if err := json.Unmarshal([]byte(renderJSON(tsconfig(m))), &config); err != nil {
+		t.Fatalf("tsconfig.json does not parse: %v", err)
+	}
+	if config.CompilerOptions["noEmit"] != true {
+		t.Errorf("compilerOptions = %v", config.CompilerOptions)
+	}
+}
+
+func TestGitleaksHookAndConfig(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["gitleaks"] = true
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-commit"); got != "npx lint-staged\ngitleaks protect --staged --redact\n" {
+		t.Errorf(".husky/pre-commit = %q", got)
+	}
+	if !strings.Contains(gitleaksConfig, "useDefault = true") {
+		t.Errorf(".gitleaks.toml does not extend the default rules")
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "gitleaks") {
+		t.Errorf("gitleaks is installed with npm: %v", npm)
+	}
+
+	// gitleaks is a binary, so a missing one stops the setup up front.
+	stubLookPath(t, "gitleaks")
+	m.yes = true
+	if _, err := setupGitHooks(m); err == nil || !strings.Contains(err.Error(), "missing required tools: gitleaks") {
+		t.Errorf("setup without gitleaks: %v", err)
+	}
+}
+
+func TestVerboseLogsFilesAndCommands(t *testing.T) {
+	inTempDir(t)
+	var log bytes.Buffer
+	original := logOutput
+	logOutput = &log
+	t.Cleanup(func() { logOutput = original })
+
+	m := testModel()
+	m.verbose = true
+	if err := m.writeFile(".eslintrc.js", "module.exports = {};\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.runCommand("true", "with space"); err != nil {
+		t.Fatal(err)
+	}
+	if got := log.String(); got != "writing .eslintrc.js\nrunning true \"with space\"\n" {
+		t.Errorf("log = %q", got)
+	}
+
+	log.Reset()
+	m.verbose = false
+	if err := m.runCommand("true"); err != nil {
+		t.Fatal(err)
+	}
+	if log.Len() != 0 {
+		t.Errorf("logs without -verbose: %q", log.String())
+	}
+}
+
+func TestEditorConfig(t *testing.T) {
+	m := testModel()
+	config := generateEditorConfig(m)
+	for _, want := range []string{"root = true\n", "[*]\ncharset = utf-8\nend_of_line = lf\nindent_style = space\nindent_size = 2\ninsert_final_newline = true\ntrim_trailing_whitespace = true\n", "[*.go]\nindent_style = tab\n", "[Makefile]\nindent_style = tab\n", "[*.md]\ntrim_trailing_whitespace = false\n"} {
+		if !strings.Contains(config, want) {
+			t.Errorf(".editorconfig does not contain %q:\n%s", want, config)
+		}
+	}
+	if strings.Contains(config, "[*.php]") {
+		t.Errorf("PHP gets its own section without PHPCS:\n%s", config)
+	}
+	m.enabled["phpcs"], m.phpcsStandard = true, "psr12"
+	if config := generateEditorConfig(m); !strings.Contains(config, "[*.php]\nindent_size = 4\n") {
+		t.Errorf("PSR-12 PHP files are not indented by four:\n%s", config)
+	}
+}
+
+// noRetryDelay skips the waits between install attempts.
+func noRetryDelay(t *testing.T) {
+	t.Helper()
+	original := sleep
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() { sleep = original })
+}
+
+func TestFailedSetupRemovesOnlyNewFiles(t *testing.T) {
+	dir := inTempDir(t)
+	captureInfo(t)
+	noRetryDelay(t)
+	fakeCommands(t, map[string]string{
+		"npm": `if [ "$1" = init ]; then echo '{}' > package.json; exit 0; fi; echo "network error" >&2; exit 1`,
+		"npx": fakeNpx,
+	})
+	writeConfig(t, ".prettierrc.js", "custom")
+	m := testModel()
+	m.yes = true
+	m.enabled["eslint"], m.enabled["prettier"] = true, true
+	_, err := setupGitHooks(m)
+	if err == nil || !strings.Contains(err.Error(), "giving up after 3 attempts") {
+		t.Fatalf("setup error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	// The config, .gitignore and package.json this run created are gone.
+	if !slices.Equal(left, []string{".prettierrc.js"}) {
+		t.Errorf("files left after the failed setup: %v", left)
+	}
+	if got := readFile(t, ".prettierrc.js"); got != "custom" {
+		t.Errorf("the existing config was changed: %q", got)
+	}
+}
+
+func TestStylelintDefersToPrettierOnlyWithIt(t *testing.T) {
+	m := testModel()
+	m.enabled["stylelint"] = true
+	extends, _ := stylelintConfig(m).get("extends")
+	if slices.Contains(extends.([]string), "stylelint-prettier/recommended") {
+		t.Errorf("extends %v without prettier", extends)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "stylelint-prettier") {
+		t.Errorf("stylelint-prettier is installed without prettier")
+	}
+	m.enabled["prettier"] = true
+	extends, _ = stylelintConfig(m).get("extends")
+	if !slices.Equal(extends.([]string), []string{"stylelint-config-standard-scss", "stylelint-prettier/recommended"}) {
+		t.Errorf("extends = %v", extends)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "stylelint-prettier") {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestEslintDefersToPrettier(t *testing.T) {
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"], m.react = true, true, true
+	extends, _ := eslintConfig(m).get("extends")
+	list := extends.([]string)
+	if list[len(list)-1] != "prettier" {
+		t.Errorf("prettier is not last in %v", list)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "eslint-config-prettier") {
+		t.Errorf("packages = %v", npm)
+	}
+	m.enabled["prettier"] = false
+	extends, _ = eslintConfig(m).get("extends")
+	if slices.Contains(extends.([]string), "prettier") {
+		t.Errorf("extends %v without prettier", extends)
+	}
+}
+
+func TestJiraKeyEndsUpInTheConfig(t *testing.T) {
+	m := atQuestion(jiraKeyQuestion)
+	m.enabled["jira-prepare-commit-msg"] = true
+	m = typeText(m, " ABC ")
+	m, _ = press(t, m, "enter")
+	pattern, _ := jiraPrepareCommitConfig(m).get("jiraTicketPattern")
+	if pattern != `(ABC-\d+)` {
+		t.Errorf("jiraTicketPattern = %v", pattern)
+	}
+	for key, want := range map[string]string{"": `([A-Z]+-\d+)`, "PROJ2": `(PROJ2-\d+)`, `((AB|CD)-\d+)`: `((AB|CD)-\d+)`} {
+		if got := jiraTicketPattern(key); got != want {
+			t.Errorf("jiraTicketPattern(%q) = %s, want %s", key, got, want)
+		}
+	}
+	// The question is only asked when the hook is selected.
+	m.enabled["jira-prepare-commit-msg"] = false
+	if m.questionApplies(jiraKeyQuestion) {
+		t.Errorf("the JIRA key is asked without jira-prepare-commit-msg")
+	}
+}
+
+func TestQuietPrintsNothingOnSuccess(t *testing.T) {
+	inTempDir(t)
+	fakePackageManagers(t)
+	info := captureInfo(t)
+	m, _ := loadTestModel(t, "-quiet", "-yes", "-eslint", "-stylelint")
+	writeConfig(t, ".eslintrc.js", "custom")
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	m.infof("%s", m.doneMessage())
+	if info.Len() != 0 {
+		t.Errorf("-quiet printed:\n%s", info.String())
+	}
+}
+
+func TestPrePushToolRunsInThePrePushHook(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["eslint"], m.enabled["prettier"] = true, true
+	prePush, err := parsePrePush([]string{"eslint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.prePush = prePush
+	setupHusky(t, m)
+	if got := readFile(t, ".husky/pre-push"); got != "npx eslint .\n" {
+		t.Errorf(".husky/pre-push = %q", got)
+	}
+	if got := readFile(t, ".husky/pre-commit"); strings.Contains(got, "eslint") {
+		t.Errorf(".husky/pre-commit runs eslint: %q", got)
+	}
+	for _, f := range lintStagedConfig(m) {
+		if slices.Contains(f.value.([]string), "eslint --fix") {
+			t.Errorf("lint-staged runs eslint on %s", f.key)
+		}
+	}
+	if _, err := parsePrePush([]string{"gitleaks"}); err == nil {
+		t.Error("a tool without a whole-project check was routed to pre-push")
+	}
+}
+
+func TestRunWithRetry(t *testing.T) {
+	var delays []time.Duration
+	original := sleep
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	t.Cleanup(func() { sleep = original })
+	var log bytes.Buffer
+	originalLog := logOutput
+	logOutput = &log
+	t.Cleanup(func() { logOutput = originalLog })
+
+	m := testModel()
+	m.verbose = true
+	calls := 0
+	err := m.runWithRetry(3, func() error {
+		if calls++; calls < 3 {
+			return errors.New("ETIMEDOUT")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err=%v after %d calls, want success on the third", err, calls)
+	}
+	if !slices.Equal(delays, []time.Duration{retryDelay, 2 * retryDelay}) {
+		t.Errorf("waited %v, want the delay doubled", delays)
+	}
+	if n := strings.Count(log.String(), "failed, retrying"); n != 2 {
+		t.Errorf("logged %d retries:\n%s", n, log.String())
+	}
+
+	calls = 0
+	err = m.runWithRetry(3, func() error {
+		calls++
+		return errors.New("ETIMEDOUT")
+	})
+	if err == nil || calls != 3 || !strings.Contains(err.Error(), "giving up after 3 attempts: ETIMEDOUT") {
+		t.Errorf("err=%v after %d calls", err, calls)
+	}
+}
+
+func TestPinInstallsExactVersions(t *testing.T) {
+	inTempDir(t)
+	log := fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.yes, m.pin = true, true
+	m.enabled["prettier"], m.enabled["phpcs"] = true, true
+	m.versions = map[string]string{"prettier": "3.0.0"}
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	commands := readFile(t, log)
+	want := "npm install --save-dev husky@" + pinnedVersions["husky"] + " lint-staged@" + pinnedVersions["lint-staged"] + " prettier@3.0.0 --save-exact\n"
+	if !strings.Contains(commands, want) {
+		t.Errorf("npm install does not pin the versions, want %q in:\n%s", want, commands)
+	}
+	want = "composer require --dev squizlabs/php_codesniffer:" + pinnedVersions["squizlabs/php_codesniffer"] + " drupal/coder:" + pinnedVersions["drupal/coder"] + "\n"
+	if !strings.Contains(commands, want) {
+		t.Errorf("composer does not pin the versions, want %q in:\n%s", want, commands)
+	}
+}
+
+func TestConfigDirMovesTheConfigs(t *testing.T) {
+	inTempDir(t)
+	fakePackageManagers(t)
+	info := captureInfo(t)
+	m := testModel()
+	m.yes = true
+	m.enabled["eslint"], m.enabled["cspell"], m.enabled["phpcs"] = true, true, true
+	m.configDir = parseConfigDir("./config/")
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"config/.lintstagedrc.js", "config/.eslintrc.js", "config/phpcs.xml", "cspell.json"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+	for _, name := range []string{".lintstagedrc.js", ".eslintrc.js", "phpcs.xml", "config/cspell.json"} {
+		if _, err := os.Stat(name); err == nil {
+			t.Errorf("%s was written", name)
+		}
+	}
+	if got := readFile(t, ".husky/pre-commit"); got != "npx lint-staged --config config/.lintstagedrc.js\n" {
+		t.Errorf(".husky/pre-commit = %q", got)
+	}
+	if got := commandsFor(t, m, "*.js"); !slices.Contains(got, "eslint --fix -c config/.eslintrc.js") {
+		t.Errorf("*.js runs %v", got)
+	}
+	if !strings.Contains(info.String(), "Keeping cspell.json in the project root") {
+		t.Errorf("no warning for the root config:\n%s", info.String())
+	}
+}
+
+// stubConfirmInstall answers the install question and records the prompts.
+func stubConfirmInstall(t *testing.T, answer bool) *[]string {
+	t.Helper()
+	var prompts []string
+	original := confirmInstall
+	confirmInstall = func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return answer
+	}
+	t.Cleanup(func() { confirmInstall = original })
+	return &prompts
+}
+
+func TestPackageList(t *testing.T) {
+	got := packageList([]string{"husky", "eslint"}, []string{"phpstan/phpstan"})
+	want := "These npm packages will be installed as devDependencies:\n  husky\n  eslint\n" +
+		"These Composer packages will be required with --dev:\n  phpstan/phpstan\n"
+	if got != want {
+		t.Errorf("packageList = %q, want %q", got, want)
+	}
+	if got := packageList(nil, []string{"phpstan/phpstan"}); strings.Contains(got, "npm") {
+		t.Errorf("packageList without npm packages = %q", got)
+	}
+}
+
+func TestInstallWaitsForConfirmation(t *testing.T) {
+	inTempDir(t)
+	log := fakePackageManagers(t)
+	captureInfo(t)
+	m := testModel()
+	m.enabled["eslint"] = true
+
+	prompts := stubConfirmInstall(t, false)
+	if _, err := setupGitHooks(m); err == nil || !strings.Contains(err.Error(), "declined") {
+		t.Errorf("declined install: %v", err)
+	}
+	if len(*prompts) != 1 {
+		t.Errorf("prompts = %q", *prompts)
+	}
+	if data, _ := os.ReadFile(log); strings.Contains(string(data), "install") {
+		t.Errorf("packages were installed after declining:\n%s", data)
+	}
+
+	prompts = stubConfirmInstall(t, true)
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	if len(*prompts) != 1 || !strings.Contains(readFile(t, log), "npm install --save-dev husky lint-staged eslint@8") {
+		t.Errorf("prompts=%q commands:\n%s", *prompts, readFile(t, log))
+	}
+
+	// -yes installs without asking.
+	prompts = stubConfirmInstall(t, false)
+	m.yes = true
+	if err := os.WriteFile("package.json", []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := setupGitHooks(m); err != nil || len(*prompts) != 0 {
+		t.Errorf("-yes: err=%v prompts=%q", err, *prompts)
+	}
+}
+
+func TestPhpcsStandards(t *testing.T) {
+	tests := []struct {
+		standard string
+		rules    []string
+		packages []string
+	}{
+		{"drupal", []string{`<rule ref="Drupal"/>`, `<rule ref="DrupalPractice"/>`, `<arg name="extensions" value="php,module,inc,install,test,profile,theme,info,yml"/>`}, []string{"squizlabs/php_codesniffer", "drupal/coder"}},
+		{"psr12", []string{`<ruleset name="PSR-12">`, `<rule ref="PSR12"/>`, `<arg name="extensions" value="php"/>`}, []string{"squizlabs/php_codesniffer"}},
+		{"wordpress", []string{`<ruleset name="WordPress">`, `<rule ref="WordPress"/>`}, []string{"squizlabs/php_codesniffer", "wp-coding-standards/wpcs"}},
+	}
+	for _, tt := range tests {
+		standard, err := parsePhpcsStandard(tt.standard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := testModel()
+		m.enabled["phpcs"], m.phpcsStandard = true, standard
+		config := generatePhpcsConfig(m)
+		for _, want := range tt.rules {
+			if !strings.Contains(config, want) {
+				t.Errorf("%s: phpcs.xml does not contain %s:\n%s", tt.standard, want, config)
+			}
+		}
+		if _, composer := m.packageLists(huskyBackend{}); !slices.Equal(composer, tt.packages) {
+			t.Errorf("%s: composer packages = %v, want %v", tt.standard, composer, tt.packages)
+		}
+	}
+	if _, err := parsePhpcsStandard("pear"); err == nil {
+		t.Error("an unknown standard is accepted")
+	}
+}
+
+func TestEnsureGitignore(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.created, m.added = &artifacts{}, &artifacts{}
+	for i := 0; i < 2; i++ {
+		if err := m.ensureGitignore([]string{"node_modules/", "vendor/"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readFile(t, ".gitignore"); got != "node_modules/\nvendor/\n" {
+		t.Errorf(".gitignore after two runs = %q", got)
+	}
+	if !slices.Equal(m.added.Files, []string{".gitignore"}) {
+		t.Errorf("the created .gitignore is not tracked: %v", m.added.Files)
+	}
+
+	// Entries the project lists in another spelling are kept as they are.
+	writeConfig(t, ".gitignore", "/node_modules\n.env")
+	m.created, m.added = &artifacts{}, &artifacts{}
+	if err := m.ensureGitignore([]string{"node_modules/", "vendor/"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, ".gitignore"); got != "/node_modules\n.env\nvendor/\n" {
+		t.Errorf(".gitignore = %q", got)
+	}
+	if len(m.added.Files) != 0 {
+		t.Errorf("an existing .gitignore is tracked as created: %v", m.added.Files)
+	}
+}
+
+func fileMode(t *testing.T, name string) os.FileMode {
+	t.Helper()
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Mode().Perm()
+}
+
+func TestHookScriptsAreExecutable(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	if err := m.writeExecutableFile("scripts/check.sh", "#!/bin/sh\n"); err != nil {
+		t.Fatal(err)
+	}
+	if mode := fileMode(t, "scripts/check.sh"); mode != 0755 {
+		t.Errorf("new script has mode %o, want 755", mode)
+	}
+
+	// Files written before the fix, or by hand, get the executable bit when
+	// the setup touches them.
+	writeConfig(t, "old.sh", "#!/bin/sh\n")
+	m.force = true
+	if err := m.writeExecutableFile("old.sh", "#!/bin/sh\necho\n"); err != nil {
+		t.Fatal(err)
+	}
+	if mode := fileMode(t, "old.sh"); mode != 0755 {
+		t.Errorf("overwritten script has mode %o, want 755", mode)
+	}
+	if err := os.MkdirAll(".husky", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, ".husky/prepare-commit-msg", "npm test\n")
+	if err := m.ensureHookCommand(".husky/prepare-commit-msg", "", prepareCommitMsgHookCommand(npm)); err != nil {
+		t.Fatal(err)
+	}
+	if mode := fileMode(t, ".husky/prepare-commit-msg"); mode != 0755 {
+		t.Errorf("hook has mode %o, want 755", mode)
+	}
+
+	if err := m.writeFile("config.json", "{}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if mode := fileMode(t, "config.json"); mode != 0644 {
+		t.Errorf("config has mode %o, want 644", mode)
+	}
+}
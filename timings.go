@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// phaseTimings records how long each phase of the setup took, for -profile.
+// A nil *phaseTimings records nothing, so the phases can be timed
+// unconditionally.
+type phaseTimings struct {
+	phases []phaseTiming
+}
+
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+var now = time.Now
+
+// start begins the named phase and returns the function that ends it. Phases
+// that fail are not recorded, as the setup stops there.
+func (t *phaseTimings) start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	started := now()
+	return func() {
+		t.phases = append(t.phases, phaseTiming{name, now().Sub(started)})
+	}
+}
+
+func (t *phaseTimings) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	var total time.Duration
+	for _, phase := range t.phases {
+		fmt.Fprintf(w, "  %s\t%s\n", phase.name, phase.duration.Round(time.Millisecond))
+		total += phase.duration
+	}
+	fmt.Fprintf(w, "  total\t%s\n", total.Round(time.Millisecond))
+	w.Flush()
+	return b.String()
+}
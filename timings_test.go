@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// tickingClock makes every call to now one second later than the last.
+func tickingClock(t *testing.T) {
+	t.Helper()
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time {
+		current = current.Add(time.Second)
+		return current
+	}
+	t.Cleanup(func() { now = original })
+}
+
+func TestPhaseTimings(t *testing.T) {
+	tickingClock(t)
+	timings := &phaseTimings{}
+	stop := timings.start("npm install")
+	stop()
+	stop = timings.start("husky setup")
+	// A phase that fails is never stopped.
+	_ = timings.start("failed")
+	stop()
+	want := []phaseTiming{{"npm install", time.Second}, {"husky setup", 2 * time.Second}}
+	if !slices.Equal(timings.phases, want) {
+		t.Errorf("phases = %v, want %v", timings.phases, want)
+	}
+	if got := timings.String(); got != "  npm install  1s\n  husky setup  2s\n  total        3s\n" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Without -profile the phases are timed on a nil *phaseTimings.
+	var none *phaseTimings
+	none.start("npm install")()
+}
+
+func TestProfileTimesTheSetupPhases(t *testing.T) {
+	inTempDir(t)
+	fakePackageManagers(t)
+	captureInfo(t)
+	m, _ := loadTestModel(t, "-profile", "-eslint", "-docroot", ".", "-yes")
+	if m.timings == nil {
+		t.Fatal("-profile does not record timings")
+	}
+	if _, err := setupGitHooks(m); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, phase := range m.timings.phases {
+		names = append(names, phase.name)
+	}
+	if want := []string{"writing files", "npm install", "husky setup", "package.json"}; !slices.Equal(names, want) {
+		t.Errorf("phases %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,656 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// listTools prints what each tool checks and installs. The details come from
+// setting up a model with only that tool selected, so the listing cannot
+// drift from what the setup does.
+func listTools(w io.Writer) error {
+	backendPackages := huskyBackend{}.packages()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOOL\tCHECKS\tINSTALLS")
+	for _, t := range toolRegistry() {
+		m := initialModel()
+		m.docroots = []string{"."}
+		m.enabled[t.name] = true
+		var checks []string
+		for _, f := range lintStagedConfig(m) {
+			checks = append(checks, f.key)
+		}
+		for _, hook := range m.hookCommands(npm) {
+			if !slices.Contains(checks, hook.hook+" hook") {
+				checks = append(checks, hook.hook+" hook")
+			}
+		}
+		npmPackages, composerPackages := m.packageLists(huskyBackend{})
+		var installs []string
+		for _, pkg := range npmPackages {
+			if !slices.Contains(backendPackages, pkg) {
+				installs = append(installs, pkg)
+			}
+		}
+		installs = append(installs, composerPackages...)
+		for _, requirement := range m.pythonRequirements() {
+			installs = append(installs, requirement+" (pip)")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.name, orDash(checks), orDash(installs))
+	}
+	return tw.Flush()
+}
+
+func orDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
+
+// tool describes a tool the wizard, the flags and the config file offer, and
+// everything the setup does for it. Adding a tool only takes an entry in
+// toolRegistry.
+type tool struct {
+	// name is the tool's flag and its key in the config file.
+	name string
+	// usage describes the tool in the wizard's checklist and in -help.
+	usage string
+	// preselected tools start selected in the wizard's checklist. followUp
+	// tools are asked about in a question of their own after it, as they
+	// only apply together with other tools.
+	preselected bool
+	followUp    bool
+	// replaces lists the tools this one turns off, so that they do not
+	// fight over the same files.
+	replaces []string
+	packages []string
+	composer []string
+	// pip packages are written to requirements-dev.txt rather than
+	// installed.
+	pip []string
+	// binary is a program the tool needs on the PATH instead of packages.
+	binary string
+	// morePackages adds the packages that depend on the other choices.
+	morePackages func(m model) (npm, composer []string)
+	// extensions are the default file extensions, which -<name>-ext
+	// replaces. glob is used instead for files without an extension.
+	extensions []string
+	glob       string
+	// unscoped tools check files anywhere in the repository rather than
+	// below the docroots.
+	unscoped bool
+	// commands run on the staged files matching the glob, pointed at the
+	// tool's config. tasks replaces them for tools whose commands depend on
+	// the other choices; it gets the tool's glob.
+	commands []string
+	tasks    func(m model, glob string) []lintStagedTask
+	// hooks are run by git hooks next to the lint-staged checks.
+	hooks func(m model, pm packageManager) []hookCommand
+	// config is the tool's own config file, written with configContent
+	// when that is set. files lists any other files the tool needs.
+	config        toolConfig
+	configContent string
+	files         func(m model, pm packageManager) []configFile
+	// ci lists the tool's whole-project checks, which also run before
+	// pushing with -pre-push.
+	ci func(m model, pm packageManager) []ciStep
+	// scripts are the tool's commands in the package.json scripts.
+	scripts func(m model) toolScripts
+	// nextStep is shown after the setup, for what it cannot do itself.
+	nextStep string
+}
+
+// lintStagedTask runs commands on the staged files matching glob.
+type lintStagedTask struct {
+	glob     string
+	commands []string
+}
+
+// toolScripts are commands for the lint, lint:fix and format scripts.
+type toolScripts struct {
+	lint, fix, format string
+}
+
+// toolRegistry lists the tools in the order of the wizard's checklist. The
+// commands of tools sharing a glob run in this order too, so formatters come
+// before the checks that read what they wrote.
+func toolRegistry() []tool {
+	return []tool{
+		{
+			name:        "eslint",
+			usage:       "add eslint for JS",
+			preselected: true,
+			packages:    []string{"eslint"},
+			morePackages: func(m model) (npm, composer []string) {
+				if m.enabled["prettier"] {
+					npm = append(npm, "eslint-config-prettier")
+				}
+				if m.react {
+					npm = append(npm, "eslint-plugin-react", "eslint-plugin-react-hooks")
+				}
+				return npm, nil
+			},
+			extensions: []string{"js"},
+			commands:   []string{"eslint --fix"},
+			config:     toolConfig{"-c", formatName(".eslintrc.js", ".eslintrc.json")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("eslint"), generateEslintConfig(m)}}
+			},
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "eslint", run: m.withConfig("eslint", execCommand(pm, "eslint", m.docrootList())), runtime: runtimeNode}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{
+					lint: m.withConfig("eslint", "eslint "+m.docrootList()),
+					fix:  m.withConfig("eslint", "eslint "+m.docrootList()+" --fix"),
+				}
+			},
+		},
+		{
+			name:        "prettier",
+			usage:       "add prettier support",
+			preselected: true,
+			packages:    []string{"prettier"},
+			extensions:  []string{"js"},
+			commands:    []string{"prettier --write"},
+			config:      toolConfig{"--config", formatName(".prettierrc.js", ".prettierrc")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("prettier"), m.renderConfig(prettierConfig(m))}}
+			},
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "prettier", run: m.withConfig("prettier", execCommand(pm, "prettier", "--check", m.docrootList())), runtime: runtimeNode}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{format: m.withConfig("prettier", "prettier --write "+m.docrootList())}
+			},
+		},
+		{
+			name:        "stylelint",
+			usage:       "add stylelint for CSS and SCSS",
+			preselected: true,
+			packages:    []string{"stylelint", "stylelint-config-standard-scss"},
+			morePackages: func(m model) (npm, composer []string) {
+				if m.enabled["prettier"] {
+					npm = append(npm, "stylelint-prettier")
+				}
+				return npm, nil
+			},
+			extensions: []string{"css", "scss", "sass"},
+			commands:   []string{"stylelint --fix"},
+			config:     toolConfig{"--config", formatName(".stylelintrc.js", ".stylelintrc.json")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("stylelint"), m.renderConfig(stylelintConfig(m))}}
+			},
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "stylelint", run: m.withConfig("stylelint", execCommand(pm, "stylelint", m.stylelintGlobs())), runtime: runtimeNode}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{
+					lint: m.withConfig("stylelint", "stylelint "+m.stylelintGlobs()),
+					fix:  m.withConfig("stylelint", "stylelint "+m.stylelintGlobs()+" --fix"),
+				}
+			},
+		},
+		{
+			name:        "secretlint",
+			usage:       "add secretlint for all files",
+			preselected: true,
+			packages:    []string{"secretlint", "@secretlint/secretlint-rule-preset-recommend"},
+			glob:        "*",
+			commands:    []string{"secretlint"},
+			config:      toolConfig{"--secretlintrc", formatName(".secretlintrc.js", ".secretlintrc.json")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{
+					{m.configFile("secretlint"), m.renderConfig(secretlintConfig)},
+					{".secretlintignore", "node_modules/\nvendor/\n"},
+				}
+			},
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "secretlint", run: m.withConfig("secretlint", execCommand(pm, "secretlint", `"**/*"`)), runtime: runtimeNode}}
+			},
+		},
+		{
+			name:     "phpcs",
+			usage:    "add PHPCS and PHPCBF for PHP files, checking the -phpcs-standard",
+			composer: []string{"squizlabs/php_codesniffer"},
+			morePackages: func(m model) (npm, composer []string) {
+				return nil, m.phpcsRules().packages
+			},
+			// The standard's own extensions replace php, see phpcsExtensions.
+			extensions: []string{"php"},
+			tasks: func(m model, _ string) []lintStagedTask {
+				if m.formatsPHPWithPrettier() && !m.prePush["prettier"] {
+					// prettier-php runs the checks after formatting.
+					return nil
+				}
+				return []lintStagedTask{{extensionsGlob(m.phpcsExtensions()), m.phpcsCommands()}}
+			},
+			// phpcs is given its ruleset with --standard, which it always
+			// needs.
+			config: toolConfig{"", fixedName("phpcs.xml")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("phpcs"), generatePhpcsConfig(m)}}
+			},
+			ci: func(m model, _ packageManager) []ciStep {
+				return []ciStep{{name: "phpcs", run: "vendor/bin/phpcs --standard=" + m.configFile("phpcs"), runtime: runtimePHP}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{
+					lint: "vendor/bin/phpcs --standard=" + m.configFile("phpcs"),
+					fix:  "vendor/bin/phpcbf --standard=" + m.configFile("phpcs"),
+				}
+			},
+		},
+		{
+			name:     "prettier-php",
+			usage:    "format PHP with prettier before PHPCS checks it (needs -prettier and -phpcs)",
+			followUp: true,
+			morePackages: func(m model) (npm, composer []string) {
+				if m.formatsPHPWithPrettier() {
+					npm = append(npm, "@prettier/plugin-php")
+				}
+				return npm, nil
+			},
+			tasks: func(m model, _ string) []lintStagedTask {
+				if !m.formatsPHPWithPrettier() || m.prePush["prettier"] {
+					return nil
+				}
+				// prettier runs first so that PHPCS checks the formatted
+				// code. Both share a glob, as lint-staged runs the globs at
+				// the same time and they would write the same files. The
+				// rest of the files PHPCS checks get a glob of their own.
+				var phpcs []string
+				if !m.prePush["phpcs"] {
+					phpcs = m.phpcsCommands()
+				}
+				code := m.phpCodeExtensions()
+				tasks := []lintStagedTask{{extensionsGlob(code), append([]string{m.withConfig("prettier", "prettier --write")}, phpcs...)}}
+				var data []string
+				for _, extension := range m.phpcsExtensions() {
+					if !slices.Contains(code, extension) {
+						data = append(data, extension)
+					}
+				}
+				if len(data) > 0 && len(phpcs) > 0 {
+					tasks = append(tasks, lintStagedTask{extensionsGlob(data), phpcs})
+				}
+				return tasks
+			},
+		},
+		{
+			name:     "validate-branch-name",
+			usage:    "validate branch names using validate-branch-name",
+			packages: []string{"validate-branch-name"},
+			hooks: func(_ model, pm packageManager) []hookCommand {
+				return []hookCommand{{"pre-push", execCommand(pm, "validate-branch-name")}}
+			},
+			config: toolConfig{"", formatName(".validate-branch-namerc.js", ".validate-branch-namerc.json")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("validate-branch-name"), m.renderConfig(validateBranchNameConfig)}}
+			},
+		},
+		{
+			name:  "protect-branches",
+			usage: "reject commits to the -protected-branches",
+			hooks: func(m model, _ packageManager) []hookCommand {
+				return []hookCommand{{"pre-commit", m.protectBranchCommand()}}
+			},
+			files: func(model, packageManager) []configFile {
+				return []configFile{{protectBranchScript, protectBranchCheck}}
+			},
+		},
+		{
+			name:     "jira-prepare-commit-msg",
+			usage:    "add the ticket number to commit messages using jira-prepare-commit-msg",
+			packages: []string{"jira-prepare-commit-msg"},
+			hooks: func(_ model, pm packageManager) []hookCommand {
+				return []hookCommand{{"prepare-commit-msg", prepareCommitMsgHookCommand(pm)}}
+			},
+			config: toolConfig{"", fixedName(".jirapreparecommitmsgrc")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("jira-prepare-commit-msg"), renderJSON(jiraPrepareCommitConfig(m))}}
+			},
+		},
+		{
+			name:     "commitlint",
+			usage:    "enforce Conventional Commits using commitlint",
+			packages: []string{"@commitlint/cli", "@commitlint/config-conventional"},
+			hooks: func(m model, pm packageManager) []hookCommand {
+				return []hookCommand{{"commit-msg", m.withConfig("commitlint", commitMsgHookCommand(pm))}}
+			},
+			config: toolConfig{"--config", formatName("commitlint.config.js", ".commitlintrc.json")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("commitlint"), m.renderConfig(commitlintConfig)}}
+			},
+		},
+		{
+			// gofmt and golangci-lint are Go binaries, not npm packages.
+			name:  "go",
+			usage: "add gofmt and golangci-lint for Go files",
+			glob:  "*.go",
+			tasks: func(m model, glob string) []lintStagedTask {
+				return []lintStagedTask{{glob, []string{"gofmt -w", m.withConfig("go", "golangci-lint run")}}}
+			},
+			config:        toolConfig{"-c", fixedName(".golangci.yml")},
+			configContent: golangciConfig,
+			ci: func(m model, _ packageManager) []ciStep {
+				return []ciStep{
+					{name: "gofmt", run: "gofmt -l . | (! grep .)", runtime: runtimeGo},
+					{name: "golangci-lint", run: m.withConfig("go", "golangci-lint run"), runtime: runtimeGo},
+				}
+			},
+		},
+		{
+			name:       "python",
+			usage:      "add black, isort and flake8 for Python files",
+			pip:        []string{"black", "isort", "flake8"},
+			extensions: []string{"py"},
+			commands:   []string{"black", "isort", "flake8"},
+			files: func(model, packageManager) []configFile {
+				return []configFile{{"setup.cfg", pythonSetupConfig}}
+			},
+			ci: func(model, packageManager) []ciStep {
+				return []ciStep{
+					{name: "black", run: "black --check .", runtime: runtimePython},
+					{name: "isort", run: "isort --check-only .", runtime: runtimePython},
+					{name: "flake8", run: "flake8", runtime: runtimePython},
+				}
+			},
+		},
+		{
+			name:          "markdownlint",
+			usage:         "add markdownlint for Markdown files",
+			packages:      []string{"markdownlint-cli"},
+			extensions:    []string{"md"},
+			commands:      []string{"markdownlint --fix"},
+			config:        toolConfig{"--config", fixedName(".markdownlint.json")},
+			configContent: markdownlintConfig,
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "markdownlint", run: m.withConfig("markdownlint", execCommand(pm, "markdownlint", `"**/*.md"`, "--ignore", "node_modules")), runtime: runtimeNode}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{
+					lint: m.withConfig("markdownlint", `markdownlint "**/*.md" --ignore node_modules`),
+					fix:  m.withConfig("markdownlint", `markdownlint "**/*.md" --ignore node_modules --fix`),
+				}
+			},
+		},
+		{
+			name:       "phpstan",
+			usage:      "add PHPStan static analysis for PHP files",
+			composer:   []string{"phpstan/phpstan"},
+			extensions: []string{"php"},
+			commands:   []string{"vendor/bin/phpstan analyse --no-progress"},
+			config:     toolConfig{"-c", fixedName("phpstan.neon")},
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{m.configFile("phpstan"), generatePhpstanConfig(m)}}
+			},
+			ci: func(m model, _ packageManager) []ciStep {
+				return []ciStep{{name: "phpstan", run: m.withConfig("phpstan", "vendor/bin/phpstan analyse --no-progress"), runtime: runtimePHP}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{lint: m.withConfig("phpstan", "vendor/bin/phpstan analyse")}
+			},
+		},
+		{
+			// tsc cannot check single files against the project config, so
+			// it runs on the whole project before pushing instead of via
+			// lint-staged.
+			name:     "typescript",
+			usage:    "type-check TypeScript with tsc before pushing",
+			packages: []string{"typescript"},
+			hooks: func(_ model, pm packageManager) []hookCommand {
+				return []hookCommand{{"pre-push", execCommand(pm, "tsc", "--noEmit")}}
+			},
+			files: func(m model, _ packageManager) []configFile {
+				if _, err := os.Stat("tsconfig.json"); err == nil {
+					return nil
+				}
+				return []configFile{{"tsconfig.json", renderJSON(tsconfig(m))}}
+			},
+			ci: func(_ model, pm packageManager) []ciStep {
+				return []ciStep{{name: "tsc", run: execCommand(pm, "tsc", "--noEmit"), runtime: runtimeNode}}
+			},
+			scripts: func(model) toolScripts {
+				return toolScripts{lint: "tsc --noEmit"}
+			},
+		},
+		{
+			// gitleaks is a standalone binary, so it is checked for rather
+			// than installed.
+			name:   "gitleaks",
+			usage:  "scan staged changes for secrets with gitleaks",
+			binary: "gitleaks",
+			hooks: func(m model, _ packageManager) []hookCommand {
+				return []hookCommand{{"pre-commit", m.withConfig("gitleaks", "gitleaks protect --staged --redact")}}
+			},
+			config:        toolConfig{"--config", fixedName(".gitleaks.toml")},
+			configContent: gitleaksConfig,
+		},
+		{
+			name:          "cspell",
+			usage:         "spell-check code and docs with cspell",
+			packages:      []string{"cspell"},
+			glob:          "*",
+			commands:      []string{"cspell --no-must-find-files"},
+			config:        toolConfig{"", fixedName("cspell.json")},
+			configContent: renderJSON(cspellConfig),
+			ci: func(_ model, pm packageManager) []ciStep {
+				return []ciStep{{name: "cspell", run: execCommand(pm, "cspell", "--no-must-find-files", `"**"`), runtime: runtimeNode}}
+			},
+		},
+		{
+			// Dockerfiles usually live outside the docroot, so the glob
+			// matches them anywhere in the repository.
+			name:          "hadolint",
+			usage:         "lint Dockerfiles with hadolint",
+			binary:        "hadolint",
+			glob:          "Dockerfile*",
+			unscoped:      true,
+			commands:      []string{"hadolint"},
+			config:        toolConfig{"--config", fixedName(".hadolint.yaml")},
+			configContent: hadolintConfig,
+		},
+		{
+			// Like Dockerfiles, CI and deployment YAML sits outside the
+			// docroot.
+			name:          "yamllint",
+			usage:         "lint YAML files with yamllint",
+			pip:           []string{"yamllint"},
+			extensions:    []string{"yml", "yaml"},
+			unscoped:      true,
+			commands:      []string{"yamllint"},
+			config:        toolConfig{"-c", fixedName(".yamllint")},
+			configContent: yamllintConfig,
+			ci: func(m model, _ packageManager) []ciStep {
+				return []ciStep{{name: "yamllint", run: m.withConfig("yamllint", "yamllint ."), runtime: runtimePython}}
+			},
+		},
+		{
+			// Most CI images ship shellcheck, unlike the other standalone
+			// binaries.
+			name:     "shellcheck",
+			usage:    "check shell scripts with shellcheck",
+			binary:   "shellcheck",
+			glob:     "*.sh",
+			unscoped: true,
+			commands: []string{"shellcheck"},
+			ci: func(model, packageManager) []ciStep {
+				return []ciStep{{name: "shellcheck", run: `git ls-files -z "*.sh" | xargs -0 -r shellcheck`, runtime: runtimeShell}}
+			},
+		},
+		{
+			name:     "terraform",
+			usage:    "check the formatting of Terraform files with terraform fmt",
+			binary:   "terraform",
+			glob:     "*.tf",
+			unscoped: true,
+			commands: []string{"terraform fmt -check"},
+		},
+		{
+			name:       "biome",
+			usage:      "lint and format JS, TypeScript and JSON with Biome instead of eslint and prettier",
+			replaces:   []string{"eslint", "prettier"},
+			packages:   []string{"@biomejs/biome"},
+			extensions: []string{"js", "ts", "jsx", "tsx", "json"},
+			// Biome reports files its config ignores as errors unless told
+			// not to.
+			commands:      []string{"biome check --write --no-errors-on-unmatched"},
+			config:        toolConfig{"", fixedName("biome.json")},
+			configContent: renderJSON(biomeConfig),
+			ci: func(m model, pm packageManager) []ciStep {
+				return []ciStep{{name: "biome", run: execCommand(pm, "biome", "ci", m.docrootList()), runtime: runtimeNode}}
+			},
+			scripts: func(m model) toolScripts {
+				return toolScripts{
+					lint:   "biome check " + m.docrootList(),
+					fix:    "biome check --write " + m.docrootList(),
+					format: "biome format --write " + m.docrootList(),
+				}
+			},
+		},
+		{
+			// RuboCop is a gem, so it belongs in the project's Gemfile.
+			name:          "rubocop",
+			usage:         "lint and fix Ruby files with RuboCop",
+			extensions:    []string{"rb"},
+			commands:      []string{"rubocop -A"},
+			config:        toolConfig{"", fixedName(".rubocop.yml")},
+			configContent: rubocopConfig,
+			nextStep:      "Add RuboCop to your Gemfile: bundle add rubocop --group development",
+		},
+		{
+			name:       "license-header",
+			usage:      "check that source files start with the license header from -license-template",
+			extensions: licenseHeaderExtensions,
+			tasks: func(m model, glob string) []lintStagedTask {
+				return []lintStagedTask{{glob, []string{formatCommand("node", licenseHeaderScript, m.licenseTemplate)}}}
+			},
+			files: func(m model, _ packageManager) []configFile {
+				files := []configFile{{licenseHeaderScript, licenseHeaderCheck}}
+				if _, err := os.Stat(m.licenseTemplate); os.IsNotExist(err) {
+					files = append(files, configFile{m.licenseTemplate, licenseTemplateStarter})
+				}
+				return files
+			},
+		},
+		{
+			// Like YAML, migrations and schema files sit outside the
+			// docroot.
+			name:       "sql-formatter",
+			usage:      "format SQL files with sql-formatter",
+			packages:   []string{"sql-formatter"},
+			extensions: []string{"sql"},
+			unscoped:   true,
+			tasks: func(m model, glob string) []lintStagedTask {
+				return []lintStagedTask{{glob, []string{m.sqlFormatterCommand()}}}
+			},
+			config:        toolConfig{"--config", fixedName(".sql-formatter.json")},
+			configContent: sqlFormatterConfig,
+		},
+		{
+			// Images are optimized wherever they are, e.g. in design or
+			// docs folders next to the docroot.
+			name:       "imagemin",
+			usage:      "losslessly optimize committed images with imagemin",
+			packages:   []string{"imagemin-lint-staged"},
+			extensions: []string{"png", "jpg", "jpeg", "gif", "svg"},
+			unscoped:   true,
+			commands:   []string{"imagemin-lint-staged"},
+		},
+		{
+			name:  "github-actions",
+			usage: "add a GitHub Actions workflow running the same checks on push and pull requests",
+			files: func(m model, pm packageManager) []configFile {
+				return []configFile{{".github/workflows/lint.yml", generateGitHubWorkflow(m, pm)}}
+			},
+		},
+		{
+			name:  "gitlab-ci",
+			usage: "add a .gitlab-ci.yml running the same checks in a lint stage",
+			files: func(m model, pm packageManager) []configFile {
+				return []configFile{{".gitlab-ci.yml", generateGitLabCI(m, pm)}}
+			},
+		},
+		{
+			name:        "editorconfig",
+			usage:       "add an .editorconfig with common indentation and whitespace settings",
+			preselected: true,
+			files: func(m model, _ packageManager) []configFile {
+				return []configFile{{".editorconfig", generateEditorConfig(m)}}
+			},
+		},
+	}
+}
+
+func findTool(name string) (tool, bool) {
+	for _, t := range toolRegistry() {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+// enabledTools lists the selected tools in the order of toolRegistry.
+func (m model) enabledTools() []tool {
+	var enabled []tool
+	for _, t := range toolRegistry() {
+		if m.enabled[t.name] {
+			enabled = append(enabled, t)
+		}
+	}
+	return enabled
+}
+
+// toolGlob matches the files the tool checks.
+func (m model) toolGlob(t tool) string {
+	if t.glob != "" {
+		return t.glob
+	}
+	return m.glob(t.name, t.extensions...)
+}
+
+// lintStagedTasks lists the tool's commands for the staged files.
+func (m model) lintStagedTasks(t tool) []lintStagedTask {
+	if t.tasks != nil {
+		return t.tasks(m, m.toolGlob(t))
+	}
+	if len(t.commands) == 0 {
+		return nil
+	}
+	commands := make([]string, len(t.commands))
+	for i, command := range t.commands {
+		commands[i] = m.withConfig(t.name, command)
+	}
+	return []lintStagedTask{{m.toolGlob(t), commands}}
+}
+
+// extensionTools are the tools whose file extensions can be changed with an
+// -<tool>-ext flag.
+func extensionTools() []string {
+	var names []string
+	for _, t := range toolRegistry() {
+		if len(t.extensions) > 0 {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
+
+// prePushTools are the tools with a whole-project check, which can run before
+// pushing instead of on the staged files.
+func prePushTools() []string {
+	var names []string
+	for _, t := range toolRegistry() {
+		if t.ci != nil && (t.tasks != nil || len(t.commands) > 0) {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
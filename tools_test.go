@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCspellGlobAndConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["cspell"] = true
+	if got := commandsFor(t, m, "*"); !slices.Equal(got, []string{"cspell --no-must-find-files"}) {
+		t.Errorf("* runs %v", got)
+	}
+	spec, _ := findTool("cspell")
+	var config struct {
+		Words       []string `json:"words"`
+		IgnorePaths []string `json:"ignorePaths"`
+	}
+	if err := json.Unmarshal([]byte(spec.configContent), &config); err != nil {
+		t.Fatalf("cspell.json does not parse: %v", err)
+	}
+	if !slices.Contains(config.Words, "eslint") || !slices.Contains(config.IgnorePaths, "node_modules/**") {
+		t.Errorf("cspell.json = %+v", config)
+	}
+	if got := m.configFile("cspell"); got != "cspell.json" {
+		t.Errorf("config file = %s", got)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); !slices.Contains(npm, "cspell") {
+		t.Errorf("packages = %v", npm)
+	}
+}
+
+func TestHadolintMatcherAndConfig(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["hadolint"] = true
+	// Dockerfiles sit outside the docroot, so the glob is not scoped to it.
+	if got := commandsFor(t, m, "Dockerfile*"); !slices.Equal(got, []string{"hadolint"}) {
+		t.Errorf("Dockerfile* runs %v", got)
+	}
+	matcher := regexp.MustCompile(globToRegexp("Dockerfile*"))
+	for path, want := range map[string]bool{"Dockerfile": true, "Dockerfile.prod": true, "docker/api/Dockerfile": true, "README.md": false, "web/Dockerfile.md/x": false} {
+		if matcher.MatchString(path) != want {
+			t.Errorf("Dockerfile* matches %s: %v, want %v", path, !want, want)
+		}
+	}
+	spec, _ := findTool("hadolint")
+	var config struct {
+		Ignored []string `yaml:"ignored"`
+	}
+	if err := yaml.Unmarshal([]byte(spec.configContent), &config); err != nil {
+		t.Fatalf(".hadolint.yaml does not parse: %v", err)
+	}
+	if !slices.Equal(config.Ignored, []string{"DL3008", "DL3018"}) {
+		t.Errorf("ignored = %v", config.Ignored)
+	}
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "hadolint") {
+		t.Errorf("hadolint is installed with npm: %v", npm)
+	}
+	stubLookPath(t, "hadolint")
+	m.yes = true
+	if _, err := setupGitHooks(m); err == nil || !strings.Contains(err.Error(), "missing required tools: hadolint") {
+		t.Errorf("setup without hadolint: %v", err)
+	}
+}
+
+func TestShellcheckGlob(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.enabled["shellcheck"] = true
+	if got := commandsFor(t, m, "*.sh"); !slices.Equal(got, []string{"shellcheck"}) {
+		t.Errorf("*.sh runs %v", got)
+	}
+	stubLookPath(t, "shellcheck")
+	m.yes = true
+	if _, err := setupGitHooks(m); err == nil || !strings.Contains(err.Error(), "missing required tools: shellcheck") {
+		t.Errorf("setup without shellcheck: %v", err)
+	}
+}
+
+func TestTerraformGlob(t *testing.T) {
+	inTempDir(t)
+	m := testModel()
+	m.docroots = []string{"web"}
+	m.enabled["terraform"] = true
+	if got := commandsFor(t, m, "*.tf"); !slices.Equal(got, []string{"terraform fmt -check"}) {
+		t.Errorf("*.tf runs %v", got)
+	}
+	stubLookPath(t, "terraform")
+	m.yes = true
+	if _, err := setupGitHooks(m); err == nil || !strings.Contains(err.Error(), "missing required tools: terraform") {
+		t.Errorf("setup without terraform: %v", err)
+	}
+}
+
+func TestRubocopGlobAndConfig(t *testing.T) {
+	m := testModel()
+	m.enabled["rubocop"] = true
+	if got := commandsFor(t, m, "*.rb"); !slices.Equal(got, []string{"rubocop -A"}) {
+		t.Errorf("*.rb runs %v", got)
+	}
+	spec, _ := findTool("rubocop")
+	var config map[string]map[string]any
+	if err := yaml.Unmarshal([]byte(spec.configContent), &config); err != nil {
+		t.Fatalf(".rubocop.yml does not parse: %v", err)
+	}
+	if config["AllCops"]["NewCops"] != "enable" {
+		t.Errorf(".rubocop.yml = %v", config)
+	}
+	// RuboCop is a gem, so it is only mentioned in the next steps.
+	if npm, _ := m.packageLists(gitHooksBackend{}); slices.Contains(npm, "rubocop") {
+		t.Errorf("rubocop is installed with npm: %v", npm)
+	}
+	if !strings.Contains(m.doneMessage(), "bundle add rubocop --group development") {
+		t.Errorf("no Gemfile hint:\n%s", m.doneMessage())
+	}
+}
+
+func TestListToolsCoversEveryTool(t *testing.T) {
+	var b strings.Builder
+	if err := listTools(&b); err != nil {
+		t.Fatal(err)
+	}
+	rows := map[string]string{}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for _, line := range lines[1:] {
+		name, rest, _ := strings.Cut(line, " ")
+		rows[name] = strings.Join(strings.Fields(rest), " ")
+	}
+	for _, tool := range toolRegistry() {
+		if _, ok := rows[tool.name]; !ok {
+			t.Errorf("%s is not listed", tool.name)
+		}
+	}
+	if len(rows) != len(toolRegistry()) {
+		t.Errorf("listed %d tools, want %d", len(rows), len(toolRegistry()))
+	}
+	for name, want := range map[string]string{
+		"eslint":     "*.js eslint",
+		"phpcs":      drupalGlob + " squizlabs/php_codesniffer, drupal/coder",
+		"commitlint": "commit-msg hook @commitlint/cli, @commitlint/config-conventional",
+		"python":     "*.py black (pip), isort (pip), flake8 (pip)",
+		"hadolint":   "Dockerfile* -",
+	} {
+		if rows[name] != want {
+			t.Errorf("%s row = %q, want %q", name, rows[name], want)
+		}
+	}
+}
+
+func TestToolRegistryIsComplete(t *testing.T) {
+	names := map[string]bool{}
+	for _, tool := range toolRegistry() {
+		if names[tool.name] {
+			t.Errorf("%s is registered twice", tool.name)
+		}
+		names[tool.name] = true
+		if tool.usage == "" {
+			t.Errorf("%s has no usage", tool.name)
+		}
+		if len(tool.commands) > 0 && tool.tasks != nil {
+			t.Errorf("%s has both commands and tasks", tool.name)
+		}
+		if len(tool.commands) == 0 && tool.tasks == nil && tool.hooks == nil && tool.files == nil {
+			t.Errorf("%s neither checks files nor adds hooks or files", tool.name)
+		}
+		if tool.glob != "" && len(tool.extensions) > 0 {
+			t.Errorf("%s has both a glob and extensions", tool.name)
+		}
+		if len(tool.commands) > 0 && tool.glob == "" && len(tool.extensions) == 0 {
+			t.Errorf("%s needs either a glob or extensions", tool.name)
+		}
+		if len(tool.packages) > 0 && tool.binary != "" {
+			t.Errorf("%s has both packages and a binary", tool.name)
+		}
+		for _, pkg := range slices.Concat(tool.packages, tool.composer) {
+			if _, ok := pinnedVersions[pkg]; !ok {
+				t.Errorf("%s: %s has no pinned version", tool.name, pkg)
+			}
+		}
+		if tool.configContent != "" && tool.config.name == nil {
+			t.Errorf("%s has config content but no config", tool.name)
+		}
+		if tool.config.flag != "" && tool.config.name == nil {
+			t.Errorf("%s has a config flag but no config", tool.name)
+		}
+		for _, replaced := range tool.replaces {
+			if _, ok := findTool(replaced); !ok {
+				t.Errorf("%s replaces the unknown tool %s", tool.name, replaced)
+			}
+		}
+		if len(tool.commands) == 0 {
+			continue
+		}
+
+		// Selecting the tool alone adds its commands for its glob.
+		m := testModel()
+		m.docroots = []string{"web"}
+		m.enabled[tool.name] = true
+		glob := m.toolGlob(tool)
+		if !tool.unscoped {
+			glob = scopeGlob("web", glob)
+		}
+		if got := commandsFor(t, m, glob); !slices.Equal(got, tool.commands) {
+			t.Errorf("%s: %s runs %v, want %v", tool.name, glob, got, tool.commands)
+		}
+	}
+}
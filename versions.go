@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pinnedVersions are the versions -pin installs, tested together. Keep eslint
+// on 8, as the generated config uses the eslintrc format eslint 9 dropped.
+var pinnedVersions = map[string]string{
+	"husky":                          "9.1.7",
+	"lint-staged":                    "15.2.10",
+	"eslint":                         "8.57.0",
+	"eslint-config-prettier":         "9.1.0",
+	"eslint-plugin-react":            "7.37.2",
+	"eslint-plugin-react-hooks":      "4.6.2",
+	"prettier":                       "3.3.3",
+	"@prettier/plugin-php":           "0.22.2",
+	"@biomejs/biome":                 "1.9.4",
+	"stylelint":                      "16.10.0",
+	"stylelint-config-standard-scss": "13.1.0",
+	"stylelint-prettier":             "5.0.2",
+	"secretlint":                     "9.0.0",
+	"@secretlint/secretlint-rule-preset-recommend": "9.0.0",
+	"validate-branch-name":                         "1.3.1",
+	"jira-prepare-commit-msg":                      "1.7.2",
+	"@commitlint/cli":                              "19.5.0",
+	"@commitlint/config-conventional":              "19.5.0",
+	"markdownlint-cli":                             "0.42.0",
+	"typescript":                                   "5.6.3",
+	"sql-formatter":                                "15.4.5",
+	"imagemin-lint-staged":                         "0.5.1",
+	"cspell":                                       "8.15.4",
+	"squizlabs/php_codesniffer":                    "3.10.3",
+	"drupal/coder":                                 "8.3.26",
+	"wp-coding-standards/wpcs":                     "3.1.0",
+	"phpstan/phpstan":                              "1.12.7",
+}
+
+// defaultVersions are the version ranges installed without -pin, for
+// packages whose latest release does not work with the generated configs.
+// eslint 9 no longer reads the eslintrc format.
+var defaultVersions = map[string]string{
+	"eslint": "8",
+}
+
+// pinned appends the version to each package that has one: the -version
+// overrides, the pinned versions with -pin, or else the default ranges.
+// separator is @ for npm and : for Composer.
+func (m model) pinned(pkgs []string, separator string) []string {
+	var specs []string
+	for _, pkg := range pkgs {
+		version, ok := m.versions[pkg]
+		if !ok && m.pin {
+			version, ok = pinnedVersions[pkg]
+		}
+		if !ok {
+			version, ok = defaultVersions[pkg]
+		}
+		if ok {
+			pkg += separator + version
+		}
+		specs = append(specs, pkg)
+	}
+	return specs
+}
+
+// pinsVersions reports whether installs should save exact versions rather
+// than ranges.
+func (m model) pinsVersions() bool {
+	return m.pin || len(m.versions) > 0
+}
+
+func parseVersion(value string) (string, string, error) {
+	pkg, version, ok := strings.Cut(value, "=")
+	if !ok || pkg == "" || version == "" {
+		return "", "", fmt.Errorf("expected package=version, got %q", value)
+	}
+	return pkg, version, nil
+}